@@ -0,0 +1,513 @@
+package algorithm
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/pflag"
+	"k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/api/resource"
+	"k8s.io/kubernetes/pkg/api/unversioned"
+)
+
+//PackingPolicy holds the tunables used by the packing predicates and priorities
+type PackingPolicy struct {
+	//ResourceWeights are the relative weights of each resource for the WeightedMostUsed priority.
+	//Resources not listed keep their default weight
+	ResourceWeights map[api.ResourceName]int64 `json:"resourceWeights,omitempty"`
+	//Scoring controls the resolution of the occupancy scores
+	Scoring ScoreConfig `json:"scoring,omitempty"`
+	//UseAllocatable uses the node allocatable resources instead of the raw capacity when available
+	UseAllocatable bool `json:"useAllocatable"`
+	//OverCommitRatios are the factors of the node capacity PodOverCommitNode lets pods use.
+	//Resources not listed are not overcommitted
+	OverCommitRatios ResourceRatios `json:"overCommitRatios,omitempty"`
+	//ResourceMode selects how container requests and limits are combined into the size of a pod
+	ResourceMode ResourceMode `json:"resourceMode,omitempty"`
+	//LimitWeight is how far between the request and the limit a container is sized in the interpolate mode.
+	//0 uses the request, 1 uses the limit
+	LimitWeight float64 `json:"limitWeight,omitempty"`
+	//DefaultRequests size the resources a container neither requests nor limits.
+	//In the max mode they are also the least a container without a limit is packed as
+	DefaultRequests ResourceDefaults `json:"defaultRequests,omitempty"`
+	//NamespaceDefaultRequests override the default requests for pods in a namespace
+	NamespaceDefaultRequests map[string]ResourceDefaults `json:"namespaceDefaultRequests,omitempty"`
+	//UniqueApps limit how many copies of an app the UniqueApp predicate allows on a node
+	UniqueApps []UniqueAppPolicy `json:"uniqueApps,omitempty"`
+	//AppSpreading spreads the copies of an app across node topology domains like zones or racks
+	AppSpreading []AppSpreadingPolicy `json:"appSpreading,omitempty"`
+	//NodeConditions are the node conditions the NodeConditions predicate rejects nodes for.
+	//When not set, nodes are rejected for OutOfDisk, DiskPressure and MemoryPressure for BestEffort pods
+	NodeConditions []NodeConditionPolicy `json:"nodeConditions,omitempty"`
+	//StaleConditions controls how the condition predicates handle nodes that stopped heartbeating
+	StaleConditions StaleConditionPolicy `json:"staleConditions,omitempty"`
+	//ScaleDown selects the nodes about to be removed, which the AvoidScaleDown priority keeps pods off unless nothing
+	//else fits. The opt-in AvoidScaleDown predicate keeps pods off them even if that leaves the pods Pending
+	ScaleDown ScaleDownPolicy `json:"scaleDown,omitempty"`
+	//Cost configures how the CostMostUsed priority reads the price of nodes and weighs it against their occupancy
+	Cost CostPolicy `json:"cost,omitempty"`
+	//Spot identifies the spot and preemptible nodes for the SpotNode predicate and SpotMostUsed priority
+	Spot SpotPolicy `json:"spot,omitempty"`
+	//PodPriority sets the priority of pods. PodOverCommitNode does not count the pods of a lower priority
+	//than the pod it places and the preemption candidates report them as evictable. Off unless Namespaces is set
+	PodPriority PodPriorityPolicy `json:"podPriority,omitempty"`
+}
+
+//PodPriorityPolicy sets the priority of pods. Pods declare it with the packscheduler.alpha.kubernetes.io/priority annotation
+type PodPriorityPolicy struct {
+	//Default is the priority of pods without the annotation, or that may not set it
+	Default int `json:"default,omitempty"`
+	//Namespaces are the namespaces whose pods may set their priority with the annotation.
+	//The annotation is ignored in every other namespace, so any pod author cannot claim a high priority.
+	//When empty, every pod has the Default priority and priorities have no effect
+	Namespaces []string `json:"namespaces,omitempty"`
+}
+
+//SpotPolicy identifies spot and preemptible nodes. Pods declare their SpotPreference with the
+//packscheduler.alpha.kubernetes.io/spot annotation
+type SpotPolicy struct {
+	//Selector matches the labels of spot and preemptible nodes, e.g. cloud.google.com/gke-preemptible=true
+	Selector string `json:"selector,omitempty"`
+	//Default is the preference of pods without the annotation
+	Default SpotPreference `json:"default,omitempty"`
+}
+
+//SpotPreference is how a pod treats spot and preemptible nodes
+type SpotPreference string
+
+const (
+	//SpotForbid keeps the pod off spot nodes
+	SpotForbid SpotPreference = "forbid"
+	//SpotTolerate ranks spot nodes above other nodes of a similar occupancy, so spot capacity fills first
+	SpotTolerate SpotPreference = "tolerate"
+	//SpotPrefer ranks spot nodes above every other node, however full the other nodes are
+	SpotPrefer SpotPreference = "prefer"
+)
+
+//CostPolicy configures the CostMostUsed priority
+type CostPolicy struct {
+	//Key is the node label, or annotation when there is no such label, holding the hourly price of the node, e.g. 0.133
+	Key string `json:"key,omitempty"`
+	//Weight is how much the price per unit of resource counts against the occupancy of the node, from 0 to 1.
+	//0 only uses the occupancy, 1 only the price
+	Weight float64 `json:"weight,omitempty"`
+}
+
+//ScaleDownPolicy selects the nodes marked for removal from the cluster
+type ScaleDownPolicy struct {
+	//Annotations mark a node for scale-down when set, whatever their value.
+	//When not set, nodes are marked by the scale-down candidate annotation of this scheduler when they are drainable
+	Annotations []string `json:"annotations,omitempty"`
+	//Taints mark a node for scale-down when a taint with one of these keys is set.
+	//When not set, nodes are marked by the ToBeDeletedByClusterAutoscaler taint of the cluster autoscaler
+	Taints []string `json:"taints,omitempty"`
+}
+
+//StaleConditionPolicy treats node conditions that have not been heartbeated recently as Unknown
+type StaleConditionPolicy struct {
+	//MaxAge is how long after its last heartbeat a condition is treated as Unknown. 0 trusts conditions regardless of age
+	MaxAge unversioned.Duration `json:"maxAge,omitempty"`
+	//Action is what the condition predicates do with nodes reporting stale conditions: reject or tolerate them
+	Action StaleConditionAction `json:"action,omitempty"`
+}
+
+//StaleConditionAction is what the condition predicates do with nodes reporting stale conditions
+type StaleConditionAction string
+
+const (
+	//StaleConditionReject rejects nodes whose conditions are stale
+	StaleConditionReject StaleConditionAction = "reject"
+	//StaleConditionTolerate checks stale conditions as Unknown, which only rejects the node for policies on Unknown
+	StaleConditionTolerate StaleConditionAction = "tolerate"
+)
+
+//NodeConditionPolicy rejects nodes reporting a condition
+type NodeConditionPolicy struct {
+	//Type is the node condition, e.g. MemoryPressure or a condition set by the node problem detector
+	Type api.NodeConditionType `json:"type"`
+	//Status is the status of the condition that rejects the node. Defaults to True
+	Status api.ConditionStatus `json:"status,omitempty"`
+	//QOSClasses are the QoS classes of the pods rejected: BestEffort, Burstable and Guaranteed. Defaults to all of them
+	QOSClasses []string `json:"qosClasses,omitempty"`
+}
+
+//AppIdentity selects the pods a policy applies to and how the app of a pod is identified.
+//An app never spans namespaces
+type AppIdentity struct {
+	//Name identifies the policy in failure reasons
+	Name string `json:"name,omitempty"`
+	//Preset fills in the fields left empty from a predefined identity. "deis" identifies deis apps by version
+	Preset string `json:"preset,omitempty"`
+	//Selector opts pods into the policy, e.g. heritage=deis
+	Selector string `json:"selector,omitempty"`
+	//IdentityKeys are the label keys whose values identify an app. When empty all the labels of a pod identify its app
+	IdentityKeys []string `json:"identityKeys,omitempty"`
+}
+
+//UniqueAppPolicy limits how many copies of an app may run on a node
+type UniqueAppPolicy struct {
+	AppIdentity
+	//MaxPerNode is the most copies of an app allowed on a node. Defaults to 1
+	MaxPerNode int `json:"maxPerNode,omitempty"`
+}
+
+//AppSpreadingPolicy spreads the copies of an app across the topology domains of the nodes
+type AppSpreadingPolicy struct {
+	AppIdentity
+	//TopologyKey is the node label whose value is the domain of a node, e.g. failure-domain.beta.kubernetes.io/zone
+	TopologyKey string `json:"topologyKey"`
+	//MaxPerDomain is the most copies of an app the AppTopologySpread predicate allows in a domain. 0 is unlimited
+	MaxPerDomain int `json:"maxPerDomain,omitempty"`
+}
+
+//ResourceDefaults are the amounts used for resources a container neither requests nor limits
+type ResourceDefaults struct {
+	//Resources are fixed default amounts, e.g. {"cpu": "250m", "memory": "500Mi"}
+	Resources api.ResourceList `json:"resources,omitempty"`
+	//CapacityFractions set the default as a fraction of the capacity of the node the pod is packed on.
+	//They take precedence over Resources
+	CapacityFractions ResourceRatios `json:"capacityFractions,omitempty"`
+}
+
+//ResourceMode selects how container requests and limits are combined into the size of a pod
+type ResourceMode string
+
+const (
+	//ResourceModeRequests sizes containers by their requests
+	ResourceModeRequests ResourceMode = "requests"
+	//ResourceModeLimits sizes containers by their limits, falling back to the request when no limit is set
+	ResourceModeLimits ResourceMode = "limits"
+	//ResourceModeMax sizes containers by the larger of their request and limit
+	ResourceModeMax ResourceMode = "max"
+	//ResourceModeInterpolate sizes containers between their request and limit using the limit weight
+	ResourceModeInterpolate ResourceMode = "interpolate"
+)
+
+//ResourceRatios maps resources to a ratio. On the command line and in annotations it is written as cpu=4,memory=1.2
+type ResourceRatios map[api.ResourceName]float64
+
+func (d ResourceDefaults) validate() error {
+	if err := d.CapacityFractions.validate(); err != nil {
+		return err
+	}
+	for name, fraction := range d.CapacityFractions {
+		if fraction > 1 {
+			return fmt.Errorf("Default request fraction for %s must not be more than 1: %g", name, fraction)
+		}
+	}
+	return nil
+}
+
+// parseResourcePairs splits a list written as <resource>=<value>,<resource>=<value>
+func parseResourcePairs(value string) (map[api.ResourceName]string, error) {
+	pairs := map[api.ResourceName]string{}
+	for _, pair := range strings.Split(value, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+
+		parts := strings.SplitN(pair, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("Invalid resource value %q, expected <resource>=<value>", pair)
+		}
+		pairs[api.ResourceName(strings.TrimSpace(parts[0]))] = strings.TrimSpace(parts[1])
+	}
+	return pairs, nil
+}
+
+func parseResourceRatios(value string) (ResourceRatios, error) {
+	pairs, err := parseResourcePairs(value)
+	if err != nil {
+		return nil, err
+	}
+
+	ratios := ResourceRatios{}
+	for name, value := range pairs {
+		ratio, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid ratio for %s: %v", name, err)
+		}
+		ratios[name] = ratio
+	}
+
+	return ratios, ratios.validate()
+}
+
+func parseResourceList(value string) (api.ResourceList, error) {
+	pairs, err := parseResourcePairs(value)
+	if err != nil {
+		return nil, err
+	}
+
+	list := api.ResourceList{}
+	for name, value := range pairs {
+		quantity, err := resource.ParseQuantity(value)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid quantity for %s: %v", name, err)
+		}
+		list[name] = quantity
+	}
+	return list, nil
+}
+
+// resourceListValue sets an api.ResourceList from the command line, written as cpu=250m,memory=500Mi
+type resourceListValue struct {
+	list *api.ResourceList
+}
+
+func (v resourceListValue) String() string {
+	pairs := []string{}
+	for name, quantity := range *v.list {
+		pairs = append(pairs, fmt.Sprintf("%s=%s", name, quantity.String()))
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+//Set implements pflag.Value
+func (v resourceListValue) Set(value string) error {
+	list, err := parseResourceList(value)
+	if err != nil {
+		return err
+	}
+	*v.list = list
+	return nil
+}
+
+//Type implements pflag.Value
+func (v resourceListValue) Type() string {
+	return "resourceList"
+}
+
+func (r ResourceRatios) validate() error {
+	for name, ratio := range r {
+		if ratio <= 0 {
+			return fmt.Errorf("Ratio for %s must be positive: %g", name, ratio)
+		}
+	}
+	return nil
+}
+
+// ratio returns the ratio for the resource, or 1 if none is set
+func (r ResourceRatios) ratio(name api.ResourceName) float64 {
+	if ratio, exists := r[name]; exists {
+		return ratio
+	}
+	return 1
+}
+
+func (r *ResourceRatios) String() string {
+	pairs := []string{}
+	for name, ratio := range *r {
+		pairs = append(pairs, fmt.Sprintf("%s=%g", name, ratio))
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+//Set implements pflag.Value
+func (r *ResourceRatios) Set(value string) error {
+	ratios, err := parseResourceRatios(value)
+	if err != nil {
+		return err
+	}
+	*r = ratios
+	return nil
+}
+
+//Type implements pflag.Value
+func (r *ResourceRatios) Type() string {
+	return "resourceRatios"
+}
+
+//ScoreConfig controls the resolution of the occupancy scores
+type ScoreConfig struct {
+	//MaxScore is the score of a fully used resource. 10 keeps the bucketed scores of the default scheduler.
+	//Above 10 requires Normalize
+	MaxScore int `json:"maxScore,omitempty"`
+	//Normalize scales the scores back to the 0-10 range of the scheduler, relative to the best node
+	Normalize bool `json:"normalize,omitempty"`
+}
+
+var (
+	packingPolicyFile string
+	packingPolicy     = newDefaultPackingPolicy()
+)
+
+func newDefaultPackingPolicy() *PackingPolicy {
+	return &PackingPolicy{
+		ResourceWeights: map[api.ResourceName]int64{
+			api.ResourceCPU:    1,
+			api.ResourceMemory: 1,
+		},
+		Scoring:          defaultScoreConfig,
+		UseAllocatable:   true,
+		OverCommitRatios: ResourceRatios{},
+		ResourceMode:     ResourceModeMax,
+		LimitWeight:      0.5,
+		DefaultRequests: ResourceDefaults{
+			Resources: api.ResourceList{
+				api.ResourceCPU:    *resource.NewMilliQuantity(defaultMilliCPURequest, resource.DecimalSI),
+				api.ResourceMemory: *resource.NewQuantity(defaultMemoryRequest, resource.BinarySI),
+			},
+			CapacityFractions: ResourceRatios{},
+		},
+		StaleConditions: StaleConditionPolicy{
+			Action: StaleConditionTolerate,
+		},
+		Cost: CostPolicy{
+			Key:    defaultNodeCostKey,
+			Weight: 0.5,
+		},
+		Spot: SpotPolicy{
+			Selector: defaultSpotNodeSelector,
+			Default:  SpotTolerate,
+		},
+	}
+}
+
+//AddFlags adds the flags used to configure the packing policy to the flag set.
+//Values set in the packing policy file take precedence over the flags
+func AddFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&packingPolicyFile, "packing-policy-config-file", packingPolicyFile, "File with the JSON packing policy used by the packScheduler predicates and priorities")
+	fs.BoolVar(&packingPolicy.UseAllocatable, "use-node-allocatable", packingPolicy.UseAllocatable, "Use the node allocatable resources instead of the capacity when packing. Falls back to capacity for resources a node does not report as allocatable")
+	fs.StringVar((*string)(&packingPolicy.ResourceMode), "packing-resource-mode", string(packingPolicy.ResourceMode), "How container requests and limits are combined when packing: requests, limits, max or interpolate")
+	fs.Float64Var(&packingPolicy.LimitWeight, "packing-limit-weight", packingPolicy.LimitWeight, "Weight of the limit between 0 (request) and 1 (limit) for the interpolate packing resource mode")
+	fs.Var(resourceListValue{&packingPolicy.DefaultRequests.Resources}, "default-requests", "Amount of each resource used for containers that neither request nor limit it, e.g. cpu=250m,memory=500Mi")
+	fs.Var(&packingPolicy.DefaultRequests.CapacityFractions, "default-request-fractions", "Default requests as a fraction of the capacity of the node the pod is packed on, e.g. cpu=0.05. Takes precedence over --default-requests")
+	fs.DurationVar(&packingPolicy.StaleConditions.MaxAge.Duration, "node-condition-max-age", packingPolicy.StaleConditions.MaxAge.Duration, "Node conditions not heartbeated for longer than this are treated as Unknown by the condition predicates. 0 trusts conditions regardless of age")
+	fs.StringVar((*string)(&packingPolicy.StaleConditions.Action), "stale-node-condition-action", string(packingPolicy.StaleConditions.Action), "What the condition predicates do with nodes reporting stale conditions: reject or tolerate")
+	fs.StringSliceVar(&packingPolicy.ScaleDown.Annotations, "scale-down-annotations", packingPolicy.ScaleDown.Annotations, "Node annotations marking a node for scale-down, which AvoidScaleDown keeps pods off. Defaults to "+ScaleDownCandidateAnnotation+" on drainable nodes")
+	fs.StringSliceVar(&packingPolicy.ScaleDown.Taints, "scale-down-taints", packingPolicy.ScaleDown.Taints, "Keys of the node taints marking a node for scale-down, which AvoidScaleDown keeps pods off. Defaults to "+clusterAutoscalerTaint)
+	fs.StringVar(&packingPolicy.Cost.Key, "node-cost-key", packingPolicy.Cost.Key, "Node label or annotation holding the hourly price of the node for the CostMostUsed priority")
+	fs.Float64Var(&packingPolicy.Cost.Weight, "node-cost-weight", packingPolicy.Cost.Weight, "Weight of the price per unit of resource between 0 and 1 against the occupancy of the node for the CostMostUsed priority")
+	fs.StringVar(&packingPolicy.Spot.Selector, "spot-node-selector", packingPolicy.Spot.Selector, "Label selector matching the spot and preemptible nodes")
+	fs.StringVar((*string)(&packingPolicy.Spot.Default), "default-spot-preference", string(packingPolicy.Spot.Default), "How pods without the "+spotPreferenceAnnotation+" annotation treat spot nodes: forbid, tolerate or prefer")
+	fs.IntVar(&packingPolicy.PodPriority.Default, "default-pod-priority", packingPolicy.PodPriority.Default, "Priority of pods without the "+podPriorityAnnotation+" annotation, or outside the --pod-priority-namespaces")
+	fs.StringSliceVar(&packingPolicy.PodPriority.Namespaces, "pod-priority-namespaces", packingPolicy.PodPriority.Namespaces, "Namespaces whose pods may set their priority with the "+podPriorityAnnotation+" annotation. Pod priorities are off when empty")
+	fs.Var(&packingPolicy.OverCommitRatios, "overcommit-ratios", "Factors of the node capacity pods may use per resource, e.g. cpu=4,memory=1.2. Nodes can override them with the "+overCommitRatiosAnnotation+" annotation")
+}
+
+//LoadPolicy reads the packing policy file given on the command line, if any, and validates the resulting policy
+func LoadPolicy() error {
+	if packingPolicyFile == "" {
+		if err := packingPolicy.Validate(); err != nil {
+			return err
+		}
+		registerPlacementScores()
+		return nil
+	}
+
+	data, err := ioutil.ReadFile(packingPolicyFile)
+	if err != nil {
+		return fmt.Errorf("Unable to read packing policy %s: %v", packingPolicyFile, err)
+	}
+
+	if err := loadPackingPolicy(data, packingPolicy); err != nil {
+		return err
+	}
+	registerPlacementScores()
+	return nil
+}
+
+func loadPackingPolicy(data []byte, policy *PackingPolicy) error {
+	if err := json.Unmarshal(data, policy); err != nil {
+		return fmt.Errorf("Unable to parse packing policy: %v", err)
+	}
+
+	return policy.Validate()
+}
+
+//Validate checks the packing policy for values the predicates and priorities cannot use
+func (p *PackingPolicy) Validate() error {
+	for name, weight := range p.ResourceWeights {
+		if weight < 0 {
+			return fmt.Errorf("Resource weight for %s must not be negative: %d", name, weight)
+		}
+	}
+
+	if err := p.OverCommitRatios.validate(); err != nil {
+		return err
+	}
+
+	switch p.ResourceMode {
+	case ResourceModeRequests, ResourceModeLimits, ResourceModeMax, ResourceModeInterpolate:
+	default:
+		return fmt.Errorf("Unknown resource mode: %q", p.ResourceMode)
+	}
+
+	if p.LimitWeight < 0 || p.LimitWeight > 1 {
+		return fmt.Errorf("Limit weight must be between 0 and 1: %g", p.LimitWeight)
+	}
+
+	if err := p.DefaultRequests.validate(); err != nil {
+		return err
+	}
+	for namespace, defaults := range p.NamespaceDefaultRequests {
+		if err := defaults.validate(); err != nil {
+			return fmt.Errorf("Namespace %s: %v", namespace, err)
+		}
+	}
+
+	for _, policy := range p.UniqueApps {
+		if _, err := newUniqueApp(policy); err != nil {
+			return err
+		}
+	}
+	for _, policy := range p.AppSpreading {
+		if _, err := newAppSpreading(policy); err != nil {
+			return err
+		}
+	}
+
+	for _, policy := range p.NodeConditions {
+		if _, err := newNodeCondition(policy); err != nil {
+			return err
+		}
+	}
+
+	if p.StaleConditions.MaxAge.Duration < 0 {
+		return fmt.Errorf("Node condition max age must not be negative: %v", p.StaleConditions.MaxAge.Duration)
+	}
+	switch p.StaleConditions.Action {
+	case StaleConditionReject, StaleConditionTolerate:
+	default:
+		return fmt.Errorf("Unknown stale node condition action: %q", p.StaleConditions.Action)
+	}
+
+	for _, key := range append(append([]string{}, p.ScaleDown.Annotations...), p.ScaleDown.Taints...) {
+		if key == "" {
+			return fmt.Errorf("Scale-down annotations and taints must not be empty")
+		}
+	}
+
+	if p.Cost.Key == "" {
+		return fmt.Errorf("Node cost key must not be empty")
+	}
+	if p.Cost.Weight < 0 || p.Cost.Weight > 1 {
+		return fmt.Errorf("Node cost weight must be between 0 and 1: %g", p.Cost.Weight)
+	}
+
+	if _, err := newSpotNodes(p.Spot); err != nil {
+		return err
+	}
+
+	for _, namespace := range p.PodPriority.Namespaces {
+		if namespace == "" {
+			return fmt.Errorf("Pod priority namespaces must not be empty")
+		}
+	}
+
+	if p.Scoring.MaxScore < 2 {
+		return fmt.Errorf("Max score must be at least 2: %d", p.Scoring.MaxScore)
+	}
+	if p.Scoring.MaxScore > frameworkMaxPriority && !p.Scoring.Normalize {
+		return fmt.Errorf("Max score above %d needs normalize so scores stay in the range of the scheduler: %d", frameworkMaxPriority, p.Scoring.MaxScore)
+	}
+
+	return nil
+}
@@ -0,0 +1,268 @@
+package algorithm
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/spf13/pflag"
+	"k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/api/resource"
+)
+
+func TestLoadPackingPolicy(t *testing.T) {
+	tests := []struct {
+		test     string
+		data     string
+		expected map[api.ResourceName]int64
+		err      bool
+	}{
+		{
+			test:     "Empty",
+			data:     `{}`,
+			expected: map[api.ResourceName]int64{api.ResourceCPU: 1, api.ResourceMemory: 1},
+		},
+		{
+			test:     "MemoryWeighted",
+			data:     `{"resourceWeights": {"cpu": 1, "memory": 4}}`,
+			expected: map[api.ResourceName]int64{api.ResourceCPU: 1, api.ResourceMemory: 4},
+		},
+		{
+			test: "NegativeWeight",
+			data: `{"resourceWeights": {"cpu": -1}}`,
+			err:  true,
+		},
+		{
+			test: "MaxScoreTooSmall",
+			data: `{"scoring": {"maxScore": 1}}`,
+			err:  true,
+		},
+		{
+			test: "MaxScoreWithoutNormalize",
+			data: `{"scoring": {"maxScore": 100}}`,
+			err:  true,
+		},
+		{
+			test:     "MaxScoreNormalized",
+			data:     `{"scoring": {"maxScore": 100, "normalize": true}}`,
+			expected: map[api.ResourceName]int64{api.ResourceCPU: 1, api.ResourceMemory: 1},
+		},
+		{
+			test: "UnknownResourceMode",
+			data: `{"resourceMode": "average"}`,
+			err:  true,
+		},
+		{
+			test: "LimitWeightTooLarge",
+			data: `{"resourceMode": "interpolate", "limitWeight": 1.5}`,
+			err:  true,
+		},
+		{
+			test: "DefaultFractionTooLarge",
+			data: `{"defaultRequests": {"capacityFractions": {"cpu": 2}}}`,
+			err:  true,
+		},
+		{
+			test: "NamespaceDefaultFractionTooLarge",
+			data: `{"namespaceDefaultRequests": {"batch": {"capacityFractions": {"cpu": 2}}}}`,
+			err:  true,
+		},
+		{
+			test: "UniqueAppUnknownPreset",
+			data: `{"uniqueApps": [{"preset": "heroku"}]}`,
+			err:  true,
+		},
+		{
+			test: "AppSpreadingWithoutTopologyKey",
+			data: `{"appSpreading": [{"name": "web", "selector": "team=web"}]}`,
+			err:  true,
+		},
+		{
+			test: "NodeConditionUnknownQOS",
+			data: `{"nodeConditions": [{"type": "MemoryPressure", "qosClasses": ["Premium"]}]}`,
+			err:  true,
+		},
+		{
+			test: "UnknownStaleConditionAction",
+			data: `{"staleConditions": {"maxAge": "5m", "action": "drain"}}`,
+			err:  true,
+		},
+		{
+			test: "EmptyScaleDownTaint",
+			data: `{"scaleDown": {"taints": [""]}}`,
+			err:  true,
+		},
+		{
+			test: "NodeCostWeightOutOfRange",
+			data: `{"cost": {"weight": 2}}`,
+			err:  true,
+		},
+		{
+			test: "UnknownSpotPreference",
+			data: `{"spot": {"default": "always"}}`,
+			err:  true,
+		},
+		{
+			test: "EmptyPodPriorityNamespace",
+			data: `{"podPriority": {"namespaces": [""]}}`,
+			err:  true,
+		},
+		{
+			test: "Invalid",
+			data: `{"resourceWeights": 1}`,
+			err:  true,
+		},
+	}
+
+	for _, test := range tests {
+		policy := newDefaultPackingPolicy()
+		err := loadPackingPolicy([]byte(test.data), policy)
+		if test.err {
+			if err == nil {
+				t.Errorf("Test %s expected an error", test.test)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("Test %s had error %v", test.test, err)
+		}
+		if !reflect.DeepEqual(test.expected, policy.ResourceWeights) {
+			t.Errorf("Test %s. Expected: %v Actual: %v", test.test, test.expected, policy.ResourceWeights)
+		}
+	}
+}
+
+func TestLoadPolicyFlags(t *testing.T) {
+	tests := []struct {
+		test string
+		args []string
+		err  bool
+	}{
+		{
+			test: "Defaults",
+			args: []string{},
+		},
+		{
+			test: "Valid",
+			args: []string{"--packing-resource-mode=interpolate", "--packing-limit-weight=0.25"},
+		},
+		{
+			test: "UnknownResourceMode",
+			args: []string{"--packing-resource-mode=Requests"},
+			err:  true,
+		},
+		{
+			test: "LimitWeightTooLarge",
+			args: []string{"--packing-limit-weight=3"},
+			err:  true,
+		},
+		{
+			test: "UnknownStaleConditionAction",
+			args: []string{"--stale-node-condition-action=Reject"},
+			err:  true,
+		},
+		{
+			test: "NodeCostWeightOutOfRange",
+			args: []string{"--node-cost-weight=5"},
+			err:  true,
+		},
+	}
+
+	defer func(policy *PackingPolicy, file string) {
+		packingPolicy, packingPolicyFile = policy, file
+	}(packingPolicy, packingPolicyFile)
+
+	for _, test := range tests {
+		packingPolicy, packingPolicyFile = newDefaultPackingPolicy(), ""
+		fs := pflag.NewFlagSet(test.test, pflag.ContinueOnError)
+		AddFlags(fs)
+		if err := fs.Parse(test.args); err != nil {
+			t.Errorf("Test %s had error %v", test.test, err)
+			continue
+		}
+
+		err := LoadPolicy()
+		if test.err && err == nil {
+			t.Errorf("Test %s expected an error", test.test)
+		}
+		if !test.err && err != nil {
+			t.Errorf("Test %s had error %v", test.test, err)
+		}
+		if !test.err && placementScores == nil {
+			t.Errorf("Test %s expected the placement scores to be registered", test.test)
+		}
+	}
+}
+
+func TestResourceRatiosSet(t *testing.T) {
+	tests := []struct {
+		test     string
+		value    string
+		expected ResourceRatios
+		err      bool
+	}{
+		{
+			test:     "Empty",
+			value:    "",
+			expected: ResourceRatios{},
+		},
+		{
+			test:     "CPUAndMemory",
+			value:    "cpu=4, memory=1.2",
+			expected: ResourceRatios{api.ResourceCPU: 4, api.ResourceMemory: 1.2},
+		},
+		{
+			test:  "MissingRatio",
+			value: "cpu",
+			err:   true,
+		},
+		{
+			test:  "NotANumber",
+			value: "cpu=four",
+			err:   true,
+		},
+		{
+			test:  "Zero",
+			value: "cpu=0",
+			err:   true,
+		},
+	}
+
+	for _, test := range tests {
+		ratios := ResourceRatios{}
+		err := ratios.Set(test.value)
+		if test.err {
+			if err == nil {
+				t.Errorf("Test %s expected an error", test.test)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("Test %s had error %v", test.test, err)
+		}
+		if !reflect.DeepEqual(test.expected, ratios) {
+			t.Errorf("Test %s. Expected: %v Actual: %v", test.test, test.expected, ratios)
+		}
+	}
+}
+
+func TestResourceListValueSet(t *testing.T) {
+	list := api.ResourceList{}
+	value := resourceListValue{&list}
+	if err := value.Set("cpu=100m, memory=1Gi"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := api.ResourceList{
+		api.ResourceCPU:    resource.MustParse("100m"),
+		api.ResourceMemory: resource.MustParse("1Gi"),
+	}
+	if !reflect.DeepEqual(expected, list) {
+		t.Errorf("Expected: %v Actual: %v", expected, list)
+	}
+
+	if err := value.Set("cpu=lots"); err == nil {
+		t.Errorf("Expected an error for an invalid quantity")
+	}
+}
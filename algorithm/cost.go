@@ -0,0 +1,109 @@
+package algorithm
+
+import (
+	"strconv"
+
+	"github.com/golang/glog"
+	"k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/plugin/pkg/scheduler/algorithm"
+	schedulerapi "k8s.io/kubernetes/plugin/pkg/scheduler/api"
+	"k8s.io/kubernetes/plugin/pkg/scheduler/schedulercache"
+)
+
+// Node label or annotation holding the hourly price of a node, unless the packing policy sets another
+const defaultNodeCostKey = "packscheduler.alpha.kubernetes.io/hourly-cost"
+
+// The resources the size of a node is measured in when pricing it
+var costResources = []api.ResourceName{api.ResourceCPU, api.ResourceMemory}
+
+//NewCostMostRequestedPriority creates a priority that combines the MostUsed occupancy of a node with its price per
+//unit of resource, so the cheapest capacity is filled first. Nodes without a price get the lowest cost score
+func NewCostMostRequestedPriority(cost CostPolicy, scoring ScoreConfig) algorithm.PriorityFunction {
+	return func(pod *api.Pod, nodeNameToInfo map[string]*schedulercache.NodeInfo, nodes []*api.Node) (schedulerapi.HostPriorityList, error) {
+		unitCosts := getUnitCosts(nodes, cost.Key)
+		cheapest := float64(0)
+		for _, unitCost := range unitCosts {
+			if cheapest == 0 || unitCost < cheapest {
+				cheapest = unitCost
+			}
+		}
+
+		list := schedulerapi.HostPriorityList{}
+		for _, node := range nodes {
+			occupancy := calculateResourceOccupancy(pod, node, nodeNameToInfo[node.Name].Pods(), defaultResourceWeights, scoring.MaxScore)
+			if occupancy.Score == 0 {
+				list = append(list, occupancy)
+				continue
+			}
+
+			costScore := float64(0)
+			if unitCost, exists := unitCosts[node.Name]; exists {
+				costScore = cheapest / unitCost * float64(scoring.MaxScore)
+			}
+			score := int((1-cost.Weight)*float64(occupancy.Score) + cost.Weight*costScore + 0.5)
+			glog.V(10).Infof(
+				"%v -> %v: Cost Most Requested Priority, Occupancy: %d Cost Score: %f Score: %d",
+				pod.Name, node.Name,
+				occupancy.Score, costScore,
+				score,
+			)
+
+			list = append(list, schedulerapi.HostPriority{Host: node.Name, Score: score})
+		}
+		if scoring.Normalize {
+			normalizeScores(list)
+		}
+		return list, nil
+	}
+}
+
+// getUnitCosts returns the price per unit of resource of the priced nodes. A node's size is the mean of its
+// CPU and memory relative to the largest of the nodes, so the price of differently shaped nodes compares
+func getUnitCosts(nodes []*api.Node, key string) map[string]float64 {
+	largest := resourceList{}
+	for _, node := range nodes {
+		for _, name := range costResources {
+			if capacity := getCapacity(name, node); capacity > largest[name] {
+				largest[name] = capacity
+			}
+		}
+	}
+
+	unitCosts := map[string]float64{}
+	for _, node := range nodes {
+		price, ok := getNodeCost(node, key)
+		if !ok {
+			continue
+		}
+
+		size := float64(0)
+		for _, name := range costResources {
+			if largest[name] > 0 {
+				size += float64(getCapacity(name, node)) / float64(largest[name])
+			}
+		}
+		size /= float64(len(costResources))
+		if size == 0 {
+			continue
+		}
+		unitCosts[node.Name] = price / size
+	}
+	return unitCosts
+}
+
+// getNodeCost reads the hourly price of the node from its label, or its annotation when there is no label
+func getNodeCost(node *api.Node, key string) (float64, bool) {
+	value, exists := node.Labels[key]
+	if !exists {
+		if value, exists = node.Annotations[key]; !exists {
+			return 0, false
+		}
+	}
+
+	price, err := strconv.ParseFloat(value, 64)
+	if err != nil || price <= 0 {
+		glog.V(2).Infof("Ignoring invalid price %q of node %s", value, node.Name)
+		return 0, false
+	}
+	return price, true
+}
@@ -0,0 +1,121 @@
+package algorithm
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/kubernetes/pkg/api"
+	schedulerapi "k8s.io/kubernetes/plugin/pkg/scheduler/api"
+	"k8s.io/kubernetes/plugin/pkg/scheduler/schedulercache"
+)
+
+func makeCostNode(node string, milliCPU, memory int64, price string) *api.Node {
+	n := makeNode(node, milliCPU, memory)
+	if price != "" {
+		n.Labels = map[string]string{defaultNodeCostKey: price}
+	}
+	return n
+}
+
+func TestCostMostRequested(t *testing.T) {
+	pod := &api.Pod{
+		Spec: api.PodSpec{
+			Containers: []api.Container{
+				{
+					Resources: makeResourceRequirements(1000, 1000, 0, 0),
+				},
+			},
+		},
+	}
+	existingPod := func(node string, milliCPU, memory int64) *api.Pod {
+		return &api.Pod{
+			Spec: api.PodSpec{
+				NodeName: node,
+				Containers: []api.Container{
+					{
+						Resources: makeResourceRequirements(milliCPU, memory, 0, 0),
+					},
+				},
+			},
+		}
+	}
+	annotatedNode := makeNode("machine2", 10000, 10000)
+	annotatedNode.Annotations = map[string]string{defaultNodeCostKey: "1.0"}
+
+	tests := []struct {
+		pods         []*api.Pod
+		nodes        []*api.Node
+		weight       float64
+		expectedList schedulerapi.HostPriorityList
+		test         string
+	}{
+		{
+			/*
+				Node1 occupancy: 1 + 6000 / 10000 * 99 = 60, Unit Cost: 1.0 / 1 = 1.0, Cost Score: 0.4 / 1.0 * 100 = 40
+				Node1 Score: 0.5 * 60 + 0.5 * 40 = 50
+				Node2 occupancy: 1 + 1000 / 10000 * 99 = 10, Unit Cost: 0.4 / 1 = 0.4, Cost Score: 100
+				Node2 Score: 0.5 * 10 + 0.5 * 100 = 55
+			*/
+			nodes:        []*api.Node{makeCostNode("machine1", 10000, 10000, "1.0"), makeCostNode("machine2", 10000, 10000, "0.4")},
+			weight:       0.5,
+			expectedList: []schedulerapi.HostPriority{{Host: "machine1", Score: 50}, {Host: "machine2", Score: 55}},
+			test:         "cheaper node wins over fuller node",
+			pods:         []*api.Pod{existingPod("machine1", 5000, 5000)},
+		},
+		{
+			nodes:        []*api.Node{makeCostNode("machine1", 10000, 10000, "1.0"), makeCostNode("machine2", 10000, 10000, "0.4")},
+			weight:       0,
+			expectedList: []schedulerapi.HostPriority{{Host: "machine1", Score: 60}, {Host: "machine2", Score: 10}},
+			test:         "no cost weight is MostUsed",
+			pods:         []*api.Pod{existingPod("machine1", 5000, 5000)},
+		},
+		{
+			/*
+				Node1 occupancy: 1 + 1000 / 10000 * 99 = 10, Unit Cost: 2.0 / 1 = 2.0, Cost Score: 100
+				Node1 Score: 0.5 * 10 + 0.5 * 100 = 55
+				Node2 occupancy: 1 + 1000 / 5000 * 99 = 20, Unit Cost: 1.2 / 0.5 = 2.4, Cost Score: 2.0 / 2.4 * 100 = 83.3
+				Node2 Score: 0.5 * 20 + 0.5 * 83.3 = 52
+			*/
+			nodes:        []*api.Node{makeCostNode("machine1", 10000, 10000, "2.0"), makeCostNode("machine2", 5000, 5000, "1.2")},
+			weight:       0.5,
+			expectedList: []schedulerapi.HostPriority{{Host: "machine1", Score: 55}, {Host: "machine2", Score: 52}},
+			test:         "price is per unit of resource",
+		},
+		{
+			/*
+				Node1 occupancy: 10, no price, Cost Score: 0
+				Node1 Score: 0.5 * 10 = 5
+				Node2 occupancy: 10, price annotation, Cost Score: 100
+				Node2 Score: 0.5 * 10 + 0.5 * 100 = 55
+			*/
+			nodes:        []*api.Node{makeCostNode("machine1", 10000, 10000, ""), annotatedNode},
+			weight:       0.5,
+			expectedList: []schedulerapi.HostPriority{{Host: "machine1", Score: 5}, {Host: "machine2", Score: 55}},
+			test:         "unpriced node scores no cost",
+		},
+		{
+			nodes:        []*api.Node{makeCostNode("machine1", 10000, 10000, "invalid"), makeCostNode("machine2", 10000, 10000, "1.0")},
+			weight:       0.5,
+			expectedList: []schedulerapi.HostPriority{{Host: "machine1", Score: 5}, {Host: "machine2", Score: 55}},
+			test:         "invalid price is ignored",
+		},
+		{
+			nodes:        []*api.Node{makeCostNode("machine1", 10000, 10000, "0.1")},
+			weight:       0.5,
+			expectedList: []schedulerapi.HostPriority{{Host: "machine1", Score: 0}},
+			test:         "does not fit",
+			pods:         []*api.Pod{existingPod("machine1", 9500, 0)},
+		},
+	}
+
+	for _, test := range tests {
+		priority := NewCostMostRequestedPriority(CostPolicy{Key: defaultNodeCostKey, Weight: test.weight}, ScoreConfig{MaxScore: 100})
+		list, err := priority(pod, schedulercache.CreateNodeNameToInfoMap(test.pods, test.nodes), test.nodes)
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if !reflect.DeepEqual(test.expectedList, list) {
+			t.Errorf("%s: expected %#v, got %#v", test.test, test.expectedList, list)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package algorithm
+
+import (
+	"k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/api/unversioned"
+	"k8s.io/kubernetes/plugin/pkg/scheduler/algorithm"
+	"k8s.io/kubernetes/plugin/pkg/scheduler/factory"
+	"k8s.io/kubernetes/plugin/pkg/scheduler/schedulercache"
+)
+
+// evaluatedPredicate is a predicate run by EvaluatePod
+type evaluatedPredicate struct {
+	name      string
+	predicate algorithm.FitPredicate
+}
+
+// The predicates EvaluatePod checks nodes with, in order
+var evaluatedPredicates = []evaluatedPredicate{
+	{nodeOutOfDiskPred, NodeOutOfDisk},
+	{podOverCommitNodePred, PodOverCommitNode},
+	{deisUniqueAppPred, UniqueDeisApp},
+}
+
+//EvaluatePod runs the NodeOutOfDisk, PodOverCommitNode and DeisUniqueApp predicates for a pod against every node,
+//and scores the nodes that pass them all with MostUsed. 'pods' are the pods already assigned to the nodes.
+//Nothing is bound and nothing is recorded by explain mode
+func EvaluatePod(pod *api.Pod, nodes []*api.Node, pods []*api.Pod) (*DecisionRecord, error) {
+	record := &DecisionRecord{
+		Namespace: pod.Namespace,
+		Name:      pod.Name,
+		UID:       pod.UID,
+		Time:      unversioned.Now(),
+		Nodes:     map[string]*NodeDecision{},
+	}
+
+	nodeNameToInfo := schedulercache.CreateNodeNameToInfoMap(pods, nodes)
+	fitting := []*api.Node{}
+	for _, node := range nodes {
+		info := nodeNameToInfo[node.Name]
+		decision := &NodeDecision{}
+		record.Nodes[node.Name] = decision
+
+		fits := true
+		for _, p := range evaluatedPredicates {
+			fit, reasons, err := p.predicate(pod, nil, info)
+			if err != nil {
+				return nil, err
+			}
+
+			result := PredicateResult{Name: p.name, Fit: fit}
+			for _, reason := range reasons {
+				result.Reasons = append(result.Reasons, reason.GetReason())
+			}
+			decision.Predicates = append(decision.Predicates, result)
+			fits = fits && fit
+		}
+
+		if fits {
+			fitting = append(fitting, node)
+		}
+	}
+
+	// Scored by MostUsed as registered with the scheduler, so the scores follow the packing policy
+	list, err := priorityFactories["MostUsed"].Function(factory.PluginFactoryArgs{})(pod, nodeNameToInfo, fitting)
+	if err != nil {
+		return nil, err
+	}
+	for _, hostPriority := range list {
+		decision := record.Nodes[hostPriority.Host]
+		decision.Priorities = append(decision.Priorities, PriorityResult{Name: "MostUsed", Score: hostPriority.Score})
+
+		info := nodeNameToInfo[hostPriority.Host]
+		decision.Resources = getResourceOccupancy(pod, info.Node(), info.Pods())
+	}
+
+	return record, nil
+}
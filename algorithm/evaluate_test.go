@@ -0,0 +1,102 @@
+package algorithm
+
+import (
+	"strings"
+	"testing"
+
+	"k8s.io/kubernetes/pkg/api"
+)
+
+func TestEvaluatePod(t *testing.T) {
+	defer func(recorder *decisionRecorder) { decisions = recorder }(decisions)
+	sink := &testDecisionSink{}
+	decisions = newDecisionRecorder()
+	decisions.addSink(sink)
+
+	roomy := createResourceNode(4000, 10000, 0, 10)
+	roomy.Name = "roomy"
+	full := createResourceNode(2000, 10000, 0, 10)
+	full.Name = "full"
+	deis := createResourceNode(4000, 10000, 0, 10)
+	deis.Name = "deis"
+	nodes := []*api.Node{roomy, full, deis}
+
+	existing := createResourcePod(1500, 1000, 0)
+	existing.Spec.NodeName = "full"
+	running := createDeisPod("v1")
+	running.Spec.NodeName = "deis"
+
+	pod := createDeisPod("v1")
+	pod.Name = "web"
+	pod.Spec.Containers = createResourcePod(1000, 1000, 0).Spec.Containers
+
+	record, err := EvaluatePod(pod, nodes, []*api.Pod{existing, running})
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	if record.Name != "web" || len(record.Nodes) != 3 {
+		t.Fatalf("Unexpected record %+v", record)
+	}
+
+	fit := record.Nodes["roomy"]
+	if len(fit.Predicates) != len(evaluatedPredicates) {
+		t.Errorf("Expected every predicate to run on roomy: %+v", fit.Predicates)
+	}
+	for _, result := range fit.Predicates {
+		if !result.Fit {
+			t.Errorf("Expected %s to pass on roomy: %v", result.Name, result.Reasons)
+		}
+	}
+	if len(fit.Priorities) != 1 || fit.Priorities[0].Name != "MostUsed" || fit.Priorities[0].Score == 0 {
+		t.Errorf("Expected a MostUsed score for roomy: %+v", fit.Priorities)
+	}
+	if len(fit.Resources) == 0 || fit.Resources[0].Requested != 1000 {
+		t.Errorf("Unexpected occupancy for roomy: %+v", fit.Resources)
+	}
+
+	tests := []struct {
+		node   string
+		reason string
+	}{
+		{node: "full", reason: "PodOverCommitNode-CPU"},
+		{node: "deis", reason: "DeisUniqueApp"},
+	}
+	for _, test := range tests {
+		decision := record.Nodes[test.node]
+		if len(decision.Priorities) != 0 {
+			t.Errorf("Expected no score for %s: %+v", test.node, decision.Priorities)
+		}
+
+		found := false
+		for _, result := range decision.Predicates {
+			for _, reason := range result.Reasons {
+				found = found || strings.HasPrefix(reason, test.reason)
+			}
+		}
+		if !found {
+			t.Errorf("Expected reason %s for %s: %+v", test.reason, test.node, decision.Predicates)
+		}
+	}
+
+	sendQueuedDecisions(decisions)
+	if len(sink.records) != 0 || decisions.current != nil {
+		t.Errorf("Expected evaluating a pod to leave explain mode alone")
+	}
+}
+
+func TestEvaluatePodScoring(t *testing.T) {
+	defer func(scoring ScoreConfig) { packingPolicy.Scoring = scoring }(packingPolicy.Scoring)
+	packingPolicy.Scoring = ScoreConfig{MaxScore: 100, Normalize: true}
+
+	nodes := []*api.Node{createResourceNode(4000, 10000, 0, 10)}
+	record, err := EvaluatePod(createResourcePod(1000, 1000, 0), nodes, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	priorities := record.Nodes["machine1"].Priorities
+	if len(priorities) != 1 || priorities[0].Score != frameworkMaxPriority {
+		t.Errorf("Expected the normalized MostUsed score of the packing policy, got %+v", priorities)
+	}
+}
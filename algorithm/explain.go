@@ -0,0 +1,308 @@
+package algorithm
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+	"k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/api/unversioned"
+	"k8s.io/kubernetes/pkg/types"
+	"k8s.io/kubernetes/plugin/pkg/scheduler/algorithm"
+	schedulerapi "k8s.io/kubernetes/plugin/pkg/scheduler/api"
+	"k8s.io/kubernetes/plugin/pkg/scheduler/schedulercache"
+)
+
+const (
+	// How long a decision record is kept open after the last predicate or priority ran for its pod
+	decisionIdleTimeout = 2 * time.Second
+	// How many finished decision records may wait for the sinks. Records are dropped once it is full
+	decisionQueueSize = 100
+)
+
+//DecisionRecord explains how the packing predicates and priorities judged each node for a pod
+type DecisionRecord struct {
+	Namespace string                   `json:"namespace"`
+	Name      string                   `json:"name"`
+	UID       types.UID                `json:"uid,omitempty"`
+	Time      unversioned.Time         `json:"time"`
+	Nodes     map[string]*NodeDecision `json:"nodes"`
+}
+
+//NodeDecision holds the results of the packing predicates and priorities for a node
+type NodeDecision struct {
+	Predicates []PredicateResult `json:"predicates,omitempty"`
+	Priorities []PriorityResult  `json:"priorities,omitempty"`
+	//Resources is the occupancy of the node once the pod is placed on it, as seen by the priorities
+	Resources []ResourceOccupancy `json:"resources,omitempty"`
+}
+
+//PredicateResult is the outcome of a predicate for a node
+type PredicateResult struct {
+	Name    string   `json:"name"`
+	Fit     bool     `json:"fit"`
+	Reasons []string `json:"reasons,omitempty"`
+}
+
+//PriorityResult is the unweighted score a priority gave a node
+type PriorityResult struct {
+	Name  string `json:"name"`
+	Score int    `json:"score"`
+}
+
+//ResourceOccupancy is the amount of a resource requested on a node against its capacity
+type ResourceOccupancy struct {
+	Resource  api.ResourceName `json:"resource"`
+	Requested int64            `json:"requested"`
+	Capacity  int64            `json:"capacity"`
+}
+
+//DecisionSink receives a decision record for every pod once the packing predicates and priorities are done with it
+type DecisionSink interface {
+	RecordDecision(record *DecisionRecord) error
+}
+
+// decisionRecorder collects the results of the predicates and priorities for the pod being scheduled.
+// The scheduler handles one pod at a time, so a record is complete once another pod shows up or
+// nothing has been recorded for a while. Finished records are queued for the sinks, so a slow sink
+// does not hold up the predicates and priorities of the next pod.
+type decisionRecorder struct {
+	lock      sync.Mutex
+	sinks     []DecisionSink
+	current   *DecisionRecord
+	updated   time.Time
+	queue     chan *DecisionRecord
+	startOnce sync.Once
+}
+
+var decisions = newDecisionRecorder()
+
+func newDecisionRecorder() *decisionRecorder {
+	return &decisionRecorder{queue: make(chan *DecisionRecord, decisionQueueSize)}
+}
+
+//AddDecisionSink enables explain mode. Every pod's decision record is sent to the sink
+func AddDecisionSink(sink DecisionSink) {
+	decisions.addSink(sink)
+	decisions.startOnce.Do(func() {
+		go func() {
+			for range time.Tick(decisionIdleTimeout / 2) {
+				decisions.flushIdle()
+			}
+		}()
+		go decisions.run()
+	})
+}
+
+// run sends the queued records to the sinks
+func (r *decisionRecorder) run() {
+	for record := range r.queue {
+		r.send(record)
+	}
+}
+
+func (r *decisionRecorder) addSink(sink DecisionSink) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.sinks = append(r.sinks, sink)
+}
+
+func (r *decisionRecorder) enabled() bool {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return len(r.sinks) > 0
+}
+
+// node returns the decision for the node in the record of 'pod', finishing the record of the previous pod.
+// The lock must be held.
+func (r *decisionRecorder) node(pod *api.Pod, name string) (*NodeDecision, *DecisionRecord) {
+	var finished *DecisionRecord
+	if r.current != nil && !isSamePod(r.current, pod) {
+		finished = r.current
+		r.current = nil
+	}
+	if r.current == nil {
+		r.current = &DecisionRecord{
+			Namespace: pod.Namespace,
+			Name:      pod.Name,
+			UID:       pod.UID,
+			Time:      unversioned.Now(),
+			Nodes:     map[string]*NodeDecision{},
+		}
+	}
+	r.updated = time.Now()
+
+	decision, exists := r.current.Nodes[name]
+	if !exists {
+		decision = &NodeDecision{}
+		r.current.Nodes[name] = decision
+	}
+	return decision, finished
+}
+
+func (r *decisionRecorder) recordPredicate(pod *api.Pod, node string, predicate string, fit bool, reasons []algorithm.PredicateFailureReason) {
+	r.lock.Lock()
+	decision, finished := r.node(pod, node)
+	result := PredicateResult{Name: predicate, Fit: fit}
+	for _, reason := range reasons {
+		result.Reasons = append(result.Reasons, reason.GetReason())
+	}
+	decision.Predicates = append(decision.Predicates, result)
+	r.lock.Unlock()
+
+	r.emit(finished)
+}
+
+func (r *decisionRecorder) recordPriority(pod *api.Pod, nodeNameToInfo map[string]*schedulercache.NodeInfo, priority string, list schedulerapi.HostPriorityList) {
+	r.lock.Lock()
+	var finished *DecisionRecord
+	for _, hostPriority := range list {
+		decision, previous := r.node(pod, hostPriority.Host)
+		if previous != nil {
+			finished = previous
+		}
+		decision.Priorities = append(decision.Priorities, PriorityResult{Name: priority, Score: hostPriority.Score})
+
+		info, exists := nodeNameToInfo[hostPriority.Host]
+		if decision.Resources == nil && exists && info.Node() != nil {
+			decision.Resources = getResourceOccupancy(pod, info.Node(), info.Pods())
+		}
+	}
+	r.lock.Unlock()
+
+	r.emit(finished)
+}
+
+// flushIdle finishes the current record if nothing was recorded for it recently
+func (r *decisionRecorder) flushIdle() {
+	r.lock.Lock()
+	var finished *DecisionRecord
+	if r.current != nil && time.Since(r.updated) >= decisionIdleTimeout {
+		finished = r.current
+		r.current = nil
+	}
+	r.lock.Unlock()
+
+	r.emit(finished)
+}
+
+// emit queues a finished record for the sinks, dropping it when the sinks are falling behind
+func (r *decisionRecorder) emit(record *DecisionRecord) {
+	if record == nil {
+		return
+	}
+
+	select {
+	case r.queue <- record:
+	default:
+		glog.V(4).Infof("Dropping the decision record of Pod %s/%s, the decision sinks are falling behind", record.Namespace, record.Name)
+	}
+}
+
+func (r *decisionRecorder) send(record *DecisionRecord) {
+	r.lock.Lock()
+	sinks := r.sinks
+	r.lock.Unlock()
+
+	for _, sink := range sinks {
+		if err := sink.RecordDecision(record); err != nil {
+			glog.Warningf("Unable to record the decision for Pod %s/%s: %v", record.Namespace, record.Name, err)
+		}
+	}
+}
+
+func isSamePod(record *DecisionRecord, pod *api.Pod) bool {
+	if record.UID != "" || pod.UID != "" {
+		return record.UID == pod.UID
+	}
+	return record.Namespace == pod.Namespace && record.Name == pod.Name
+}
+
+// getResourceOccupancy returns the occupancy of each resource on 'node' once 'pod' is added to the 'pods' already on it
+func getResourceOccupancy(pod *api.Pod, node *api.Node, pods []*api.Pod) []ResourceOccupancy {
+	total := getResourcesAfterPlacement(pod, node, pods)
+
+	occupancy := []ResourceOccupancy{}
+	for _, name := range total.names() {
+		occupancy = append(occupancy, ResourceOccupancy{
+			Resource:  name,
+			Requested: total[name],
+			Capacity:  getCapacity(name, node),
+		})
+	}
+	return occupancy
+}
+
+//Summary describes the decision in a single line, e.g. for a pod event
+func (r *DecisionRecord) Summary() string {
+	names := []string{}
+	for name := range r.Nodes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	parts := []string{}
+	for _, name := range names {
+		decision := r.Nodes[name]
+
+		details := []string{}
+		for _, predicate := range decision.Predicates {
+			if !predicate.Fit {
+				details = append(details, predicate.Reasons...)
+			}
+		}
+		if len(details) == 0 {
+			for _, priority := range decision.Priorities {
+				details = append(details, fmt.Sprintf("%s=%d", priority.Name, priority.Score))
+			}
+		}
+		parts = append(parts, fmt.Sprintf("%s (%s)", name, strings.Join(details, ", ")))
+	}
+	return strings.Join(parts, "; ")
+}
+
+// jsonDecisionSink writes every decision record as a line of JSON
+type jsonDecisionSink struct {
+	lock sync.Mutex
+	out  io.Writer
+}
+
+//NewJSONDecisionSink creates a sink writing every decision record to 'out' as a line of JSON
+func NewJSONDecisionSink(out io.Writer) DecisionSink {
+	return &jsonDecisionSink{out: out}
+}
+
+func (s *jsonDecisionSink) RecordDecision(record *DecisionRecord) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	_, err = s.out.Write(append(data, '\n'))
+	return err
+}
+
+// logDecisionSink writes every decision record to the scheduler log as JSON
+type logDecisionSink struct{}
+
+//NewLogDecisionSink creates a sink writing every decision record to the scheduler log as JSON
+func NewLogDecisionSink() DecisionSink {
+	return logDecisionSink{}
+}
+
+func (logDecisionSink) RecordDecision(record *DecisionRecord) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	glog.Infof("Packing decision for Pod %s/%s: %s", record.Namespace, record.Name, data)
+	return nil
+}
@@ -0,0 +1,181 @@
+package algorithm
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/types"
+	"k8s.io/kubernetes/plugin/pkg/scheduler/schedulercache"
+)
+
+type testDecisionSink struct {
+	records []*DecisionRecord
+}
+
+func (s *testDecisionSink) RecordDecision(record *DecisionRecord) error {
+	s.records = append(s.records, record)
+	return nil
+}
+
+// sendQueuedDecisions sends the records queued so far to the sinks, as the recorder's goroutine would
+func sendQueuedDecisions(r *decisionRecorder) {
+	for {
+		select {
+		case record := <-r.queue:
+			r.send(record)
+		default:
+			return
+		}
+	}
+}
+
+func TestExplainDecisions(t *testing.T) {
+	defer func(recorder *decisionRecorder) { decisions = recorder }(decisions)
+	sink := &testDecisionSink{}
+	decisions = newDecisionRecorder()
+	decisions.addSink(sink)
+
+	nodes := []*api.Node{
+		createResourceNode(4000, 10000, 0, 10),
+		createResourceNode(2000, 10000, 0, 10),
+	}
+	nodes[1].Name = "machine2"
+	pod := createResourcePod(3000, 1000, 0)
+	pod.Name = "web"
+	pod.UID = types.UID("web-uid")
+
+	predicate := instrumentPredicate(podOverCommitNodePred, PodOverCommitNode)
+	nodeNameToInfo := map[string]*schedulercache.NodeInfo{}
+	fitting := []*api.Node{}
+	for _, node := range nodes {
+		info := schedulercache.NewNodeInfo()
+		info.SetNode(node)
+		nodeNameToInfo[node.Name] = info
+
+		if fit, _, _ := predicate(pod, nil, info); fit {
+			fitting = append(fitting, node)
+		}
+	}
+
+	priority := instrumentPriority("MostUsed", MostRequestedPriority)
+	if _, err := priority(pod, nodeNameToInfo, fitting); err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	if len(sink.records) != 0 {
+		t.Fatalf("Expected the record to stay open until the next pod, got %d records", len(sink.records))
+	}
+
+	next := createResourcePod(1000, 1000, 0)
+	next.UID = types.UID("next-uid")
+	predicate(next, nil, nodeNameToInfo["machine1"])
+	sendQueuedDecisions(decisions)
+	if len(sink.records) != 1 {
+		t.Fatalf("Expected 1 record, got %d", len(sink.records))
+	}
+
+	record := sink.records[0]
+	if record.Name != "web" || record.UID != "web-uid" {
+		t.Errorf("Unexpected pod in record %s %s", record.Name, record.UID)
+	}
+
+	fit := record.Nodes["machine1"]
+	if fit == nil || len(fit.Predicates) != 1 || !fit.Predicates[0].Fit {
+		t.Fatalf("Expected machine1 to fit: %+v", fit)
+	}
+	if len(fit.Priorities) != 1 || fit.Priorities[0].Name != "MostUsed" || fit.Priorities[0].Score == 0 {
+		t.Errorf("Expected a MostUsed score for machine1: %+v", fit.Priorities)
+	}
+	if len(fit.Resources) != 2 || fit.Resources[0].Resource != api.ResourceCPU || fit.Resources[0].Requested != 3000 || fit.Resources[0].Capacity != 4000 {
+		t.Errorf("Unexpected occupancy for machine1: %+v", fit.Resources)
+	}
+
+	rejected := record.Nodes["machine2"]
+	if rejected == nil || len(rejected.Predicates) != 1 || rejected.Predicates[0].Fit {
+		t.Fatalf("Expected machine2 to be rejected: %+v", rejected)
+	}
+	if len(rejected.Priorities) != 0 {
+		t.Errorf("Expected no scores for machine2: %+v", rejected.Priorities)
+	}
+	if len(rejected.Predicates[0].Reasons) != 1 || !strings.HasPrefix(rejected.Predicates[0].Reasons[0], "PodOverCommitNode-CPU") {
+		t.Errorf("Expected a CPU failure reason for machine2: %v", rejected.Predicates[0].Reasons)
+	}
+
+	summary := record.Summary()
+	expected := fmt.Sprintf("machine1 (MostUsed=%d); machine2 (%s)", fit.Priorities[0].Score, rejected.Predicates[0].Reasons[0])
+	if summary != expected {
+		t.Errorf("Expected summary %q, got %q", expected, summary)
+	}
+
+	decisions.updated = decisions.updated.Add(-decisionIdleTimeout)
+	decisions.flushIdle()
+	sendQueuedDecisions(decisions)
+	if len(sink.records) != 2 || sink.records[1].UID != "next-uid" {
+		t.Errorf("Expected the idle record of the next pod to be flushed, got %d records", len(sink.records))
+	}
+}
+
+func TestExplainQueueFull(t *testing.T) {
+	recorder := newDecisionRecorder()
+	sink := &testDecisionSink{}
+	recorder.addSink(sink)
+
+	// Nothing drains the queue, so the records past its size are dropped instead of blocking
+	for i := 0; i <= decisionQueueSize; i++ {
+		recorder.emit(&DecisionRecord{Namespace: "default", Name: fmt.Sprintf("pod-%d", i)})
+	}
+	sendQueuedDecisions(recorder)
+
+	if len(sink.records) != decisionQueueSize {
+		t.Fatalf("Expected %d records, got %d", decisionQueueSize, len(sink.records))
+	}
+	if last := sink.records[decisionQueueSize-1].Name; last != fmt.Sprintf("pod-%d", decisionQueueSize-1) {
+		t.Errorf("Expected the last record to be dropped, got %s last", last)
+	}
+}
+
+func TestExplainDisabled(t *testing.T) {
+	defer func(recorder *decisionRecorder) { decisions = recorder }(decisions)
+	decisions = newDecisionRecorder()
+
+	info := schedulercache.NewNodeInfo()
+	info.SetNode(createResourceNode(4000, 10000, 0, 10))
+	instrumentPredicate(podOverCommitNodePred, PodOverCommitNode)(createResourcePod(1000, 1000, 0), nil, info)
+
+	if decisions.current != nil {
+		t.Errorf("Expected nothing to be recorded without a sink")
+	}
+}
+
+func TestJSONDecisionSink(t *testing.T) {
+	out := &bytes.Buffer{}
+	sink := NewJSONDecisionSink(out)
+	record := &DecisionRecord{
+		Namespace: "default",
+		Name:      "web",
+		Nodes: map[string]*NodeDecision{
+			"machine1": {Priorities: []PriorityResult{{Name: "MostUsed", Score: 7}}},
+		},
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := sink.RecordDecision(record); err != nil {
+			t.Fatalf("Unexpected error %v", err)
+		}
+	}
+
+	lines := bytes.Split(bytes.TrimSpace(out.Bytes()), []byte("\n"))
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 lines, got %d", len(lines))
+	}
+	decoded := &DecisionRecord{}
+	if err := json.Unmarshal(lines[0], decoded); err != nil {
+		t.Fatalf("Unable to decode record %v", err)
+	}
+	if decoded.Name != "web" || decoded.Nodes["machine1"].Priorities[0].Score != 7 {
+		t.Errorf("Unexpected decoded record %+v", decoded)
+	}
+}
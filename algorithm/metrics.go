@@ -0,0 +1,241 @@
+package algorithm
+
+import (
+	"sync"
+
+	"github.com/golang/glog"
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/plugin/pkg/scheduler/algorithm"
+	pluginPred "k8s.io/kubernetes/plugin/pkg/scheduler/algorithm/predicates"
+	schedulerapi "k8s.io/kubernetes/plugin/pkg/scheduler/api"
+	"k8s.io/kubernetes/plugin/pkg/scheduler/schedulercache"
+)
+
+const metricsSubsystem = "packscheduler"
+
+var (
+	predicateFailures = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Subsystem: metricsSubsystem,
+			Name:      "predicate_failures_total",
+			Help:      "Nodes rejected by the packing predicates, by failure reason",
+		},
+		[]string{"reason"},
+	)
+	scaleDownCandidates = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Subsystem: metricsSubsystem,
+			Name:      "scale_down_candidates",
+			Help:      "Nodes that can be removed from the cluster, by whether they are empty or drainable",
+		},
+		[]string{"state"},
+	)
+
+	nodeRequestedDesc = prometheus.NewDesc(
+		prometheus.BuildFQName("", metricsSubsystem, "node_requested"),
+		"Resources used by the pods on a node as sized for packing. CPU is in cores, memory in bytes",
+		[]string{"node", "resource"}, nil,
+	)
+	nodeCapacityDesc = prometheus.NewDesc(
+		prometheus.BuildFQName("", metricsSubsystem, "node_capacity"),
+		"Resources a node can give to pods. CPU is in cores, memory in bytes",
+		[]string{"node", "resource"}, nil,
+	)
+	clusterRequestedDesc = prometheus.NewDesc(
+		prometheus.BuildFQName("", metricsSubsystem, "cluster_requested"),
+		"Resources used by the pods of all schedulable nodes as sized for packing. CPU is in cores, memory in bytes",
+		[]string{"resource"}, nil,
+	)
+	clusterCapacityDesc = prometheus.NewDesc(
+		prometheus.BuildFQName("", metricsSubsystem, "cluster_capacity"),
+		"Resources all schedulable nodes can give to pods. CPU is in cores, memory in bytes",
+		[]string{"resource"}, nil,
+	)
+	nodesDesc = prometheus.NewDesc(
+		prometheus.BuildFQName("", metricsSubsystem, "nodes"),
+		"Schedulable nodes, by whether any pod runs on them",
+		[]string{"used"}, nil,
+	)
+)
+
+// The histogram of placement scores is created once the packing policy is loaded, as its buckets follow the
+// max score of the policy
+var (
+	placementScoresOnce sync.Once
+	placementScores     prometheus.Histogram
+)
+
+func init() {
+	prometheus.MustRegister(predicateFailures)
+	prometheus.MustRegister(scaleDownCandidates)
+}
+
+// registerPlacementScores creates and registers the histogram of placement scores. Only the first
+// policy loaded sizes its buckets
+func registerPlacementScores() {
+	placementScoresOnce.Do(func() {
+		step := float64(packingPolicy.Scoring.MaxScore) / 10
+		placementScores = prometheus.NewHistogram(prometheus.HistogramOpts{
+			Subsystem: metricsSubsystem,
+			Name:      "placement_score",
+			Help:      "MostUsed score of the node each pod was bound to, at the max score of the packing policy and before normalization",
+			Buckets:   prometheus.LinearBuckets(step, step, 10),
+		})
+		prometheus.MustRegister(placementScores)
+	})
+}
+
+// placementScore is the MostUsed score of 'node' for 'pod', next to the 'pods' already on the node. It is not
+// normalized, as normalizing a single node always gives the top score
+func placementScore(pod *api.Pod, node *api.Node, pods []*api.Pod) int {
+	return calculateResourceOccupancy(pod, node, pods, defaultResourceWeights, packingPolicy.Scoring.MaxScore).Score
+}
+
+//ObservePlacement records the MostUsed score of the node a pod was bound to. 'pods' are the other pods on the node.
+//Nothing is recorded until the packing policy is loaded
+func ObservePlacement(pod *api.Pod, node *api.Node, pods []*api.Pod) {
+	if placementScores == nil {
+		return
+	}
+	placementScores.Observe(float64(placementScore(pod, node, pods)))
+}
+
+// failureReason returns the name of the predicate failure without any details, so it can be used as a label
+func failureReason(reason algorithm.PredicateFailureReason) string {
+	switch failure := reason.(type) {
+	case *overCommitFailure:
+		return failure.PredicateName
+	case *pluginPred.PredicateFailureError:
+		return failure.PredicateName
+	}
+	return reason.GetReason()
+}
+
+// instrumentPredicate counts the failures of the predicate and records its results when explain mode is enabled
+func instrumentPredicate(name string, predicate algorithm.FitPredicate) algorithm.FitPredicate {
+	return func(pod *api.Pod, meta interface{}, cacheInfo *schedulercache.NodeInfo) (bool, []algorithm.PredicateFailureReason, error) {
+		fit, reasons, err := predicate(pod, meta, cacheInfo)
+		if err != nil {
+			return fit, reasons, err
+		}
+
+		for _, reason := range reasons {
+			predicateFailures.WithLabelValues(failureReason(reason)).Inc()
+		}
+		if decisions.enabled() {
+			decisions.recordPredicate(pod, cacheInfo.Node().Name, name, fit, reasons)
+		}
+		return fit, reasons, err
+	}
+}
+
+// instrumentPriority records the scores of the priority when explain mode is enabled
+func instrumentPriority(name string, priority algorithm.PriorityFunction) algorithm.PriorityFunction {
+	return func(pod *api.Pod, nodeNameToInfo map[string]*schedulercache.NodeInfo, nodes []*api.Node) (schedulerapi.HostPriorityList, error) {
+		list, err := priority(pod, nodeNameToInfo, nodes)
+		if err != nil {
+			return list, err
+		}
+
+		if decisions.enabled() {
+			decisions.recordPriority(pod, nodeNameToInfo, name, list)
+		}
+		return list, err
+	}
+}
+
+// clusterUsage is the packing view of the resources of the schedulable nodes
+type clusterUsage struct {
+	requested map[string]resourceList
+	capacity  map[string]resourceList
+	used      map[string]bool
+}
+
+// getClusterUsage sizes the 'pods' assigned to 'nodes' the way the packing priorities do
+func getClusterUsage(nodes []*api.Node, pods []*api.Pod) *clusterUsage {
+	usage := &clusterUsage{
+		requested: map[string]resourceList{},
+		capacity:  map[string]resourceList{},
+		used:      map[string]bool{},
+	}
+
+	nodeNameToInfo := schedulercache.CreateNodeNameToInfoMap(pods, nodes)
+	for _, node := range nodes {
+		total := resourceList{}
+		for _, pod := range nodeNameToInfo[node.Name].Pods() {
+			total.add(getResourcesForPod(pod, node))
+		}
+
+		requested := resourceList{}
+		capacity := resourceList{}
+		for _, name := range total.names() {
+			requested[name] = total[name]
+			capacity[name] = getCapacity(name, node)
+		}
+
+		usage.requested[node.Name] = requested
+		usage.capacity[node.Name] = capacity
+		usage.used[node.Name] = len(nodeNameToInfo[node.Name].Pods()) > 0
+	}
+	return usage
+}
+
+// metricValue converts the amount of a resource to the base unit of its metric
+func metricValue(name api.ResourceName, value int64) float64 {
+	if name == api.ResourceCPU {
+		return float64(value) / 1000
+	}
+	return float64(value)
+}
+
+// clusterCollector reports the requested and capacity resources of every node when scraped
+type clusterCollector struct {
+	list func() ([]*api.Node, []*api.Pod, error)
+}
+
+//NewClusterCollector creates a collector reporting how full the nodes are packed. 'list' returns the
+//schedulable nodes and the pods assigned to them
+func NewClusterCollector(list func() ([]*api.Node, []*api.Pod, error)) prometheus.Collector {
+	return &clusterCollector{list: list}
+}
+
+func (c *clusterCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- nodeRequestedDesc
+	ch <- nodeCapacityDesc
+	ch <- clusterRequestedDesc
+	ch <- clusterCapacityDesc
+	ch <- nodesDesc
+}
+
+func (c *clusterCollector) Collect(ch chan<- prometheus.Metric) {
+	nodes, pods, err := c.list()
+	if err != nil {
+		glog.Warningf("Unable to list the cluster for packing metrics: %v", err)
+		return
+	}
+
+	usage := getClusterUsage(nodes, pods)
+	totalRequested := resourceList{}
+	totalCapacity := resourceList{}
+	used := 0
+	for node, requested := range usage.requested {
+		capacity := usage.capacity[node]
+		for name, value := range requested {
+			ch <- prometheus.MustNewConstMetric(nodeRequestedDesc, prometheus.GaugeValue, metricValue(name, value), node, string(name))
+			ch <- prometheus.MustNewConstMetric(nodeCapacityDesc, prometheus.GaugeValue, metricValue(name, capacity[name]), node, string(name))
+		}
+		totalRequested.add(requested)
+		totalCapacity.add(capacity)
+		if usage.used[node] {
+			used++
+		}
+	}
+
+	for name, value := range totalRequested {
+		ch <- prometheus.MustNewConstMetric(clusterRequestedDesc, prometheus.GaugeValue, metricValue(name, value), string(name))
+		ch <- prometheus.MustNewConstMetric(clusterCapacityDesc, prometheus.GaugeValue, metricValue(name, totalCapacity[name]), string(name))
+	}
+	ch <- prometheus.MustNewConstMetric(nodesDesc, prometheus.GaugeValue, float64(used), "true")
+	ch <- prometheus.MustNewConstMetric(nodesDesc, prometheus.GaugeValue, float64(len(nodes)-used), "false")
+}
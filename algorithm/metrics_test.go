@@ -0,0 +1,129 @@
+package algorithm
+
+import (
+	"testing"
+
+	"k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/plugin/pkg/scheduler/algorithm"
+)
+
+func TestFailureReason(t *testing.T) {
+	tests := []struct {
+		reason   algorithm.PredicateFailureReason
+		expected string
+	}{
+		{
+			reason:   newOverCommitFailure(api.ResourceCPU, 3000, 2000, 1),
+			expected: "PodOverCommitNode-CPU",
+		},
+		{
+			reason:   newOverCommitFailure(api.ResourceMemory, 3000, 2000, 1),
+			expected: "PodOverCommitNode-Mem",
+		},
+		{
+			reason:   podOverCommitNodePredError,
+			expected: "PodOverCommitNode",
+		},
+		{
+			reason:   nodeOutOfDiskPredError,
+			expected: "NodeOutOfDisk",
+		},
+		{
+			reason:   deisUniqueAppPredError,
+			expected: "DeisUniqueApp",
+		},
+	}
+
+	for _, test := range tests {
+		if actual := failureReason(test.reason); actual != test.expected {
+			t.Errorf("Expected: %s Actual: %s", test.expected, actual)
+		}
+	}
+}
+
+func TestGetClusterUsage(t *testing.T) {
+	used := createResourceNode(4000, 10000, 0, 10)
+	used.Name = "used"
+	empty := createResourceNode(2000, 5000, 0, 10)
+	empty.Name = "empty"
+
+	first := createResourcePod(1000, 2000, 0)
+	first.Spec.NodeName = "used"
+	second := createResourcePod(500, 1000, 0)
+	second.Spec.NodeName = "used"
+
+	usage := getClusterUsage([]*api.Node{used, empty}, []*api.Pod{first, second})
+
+	tests := []struct {
+		node      string
+		requested resourceList
+		capacity  resourceList
+		used      bool
+	}{
+		{
+			node:      "used",
+			requested: resourceList{api.ResourceCPU: 1500, api.ResourceMemory: 3000},
+			capacity:  resourceList{api.ResourceCPU: 4000, api.ResourceMemory: 10000},
+			used:      true,
+		},
+		{
+			node:      "empty",
+			requested: resourceList{api.ResourceCPU: 0, api.ResourceMemory: 0},
+			capacity:  resourceList{api.ResourceCPU: 2000, api.ResourceMemory: 5000},
+			used:      false,
+		},
+	}
+
+	for _, test := range tests {
+		for name, expected := range test.requested {
+			if actual := usage.requested[test.node][name]; actual != expected {
+				t.Errorf("Node %s. Expected %s requested: %d Actual: %d", test.node, name, expected, actual)
+			}
+		}
+		for name, expected := range test.capacity {
+			if actual := usage.capacity[test.node][name]; actual != expected {
+				t.Errorf("Node %s. Expected %s capacity: %d Actual: %d", test.node, name, expected, actual)
+			}
+		}
+		if usage.used[test.node] != test.used {
+			t.Errorf("Node %s. Expected used: %v Actual: %v", test.node, test.used, usage.used[test.node])
+		}
+	}
+
+	if len(usage.requested["used"]) != 2 {
+		t.Errorf("Expected only cpu and memory to be reported: %v", usage.requested["used"])
+	}
+}
+
+func TestPlacementScore(t *testing.T) {
+	defer func(scoring ScoreConfig) { packingPolicy.Scoring = scoring }(packingPolicy.Scoring)
+
+	node := createResourceNode(4000, 10000, 0, 10)
+	existing := createResourcePod(2000, 4000, 0)
+	existing.Spec.NodeName = "machine1"
+	pod := createResourcePod(1000, 1000, 0)
+
+	tests := []struct {
+		scoring  ScoreConfig
+		expected int
+	}{
+		/*
+			CPU: 3000 / 4000, Score: 11 - ceil(2.5) = 8
+			Memory: 5000 / 10000, Score: 11 - ceil(5) = 6
+		*/
+		{scoring: ScoreConfig{MaxScore: 10}, expected: 7},
+		/*
+			CPU: 1 + 3000 * 99 / 4000 = 75
+			Memory: 1 + 5000 * 99 / 10000 = 50
+			Normalize leaves a single placement alone
+		*/
+		{scoring: ScoreConfig{MaxScore: 100, Normalize: true}, expected: 62},
+	}
+
+	for _, test := range tests {
+		packingPolicy.Scoring = test.scoring
+		if score := placementScore(pod, node, []*api.Pod{existing}); score != test.expected {
+			t.Errorf("Scoring %+v. Expected: %d Actual: %d", test.scoring, test.expected, score)
+		}
+	}
+}
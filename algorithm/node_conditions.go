@@ -0,0 +1,153 @@
+package algorithm
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/golang/glog"
+	"k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/plugin/pkg/scheduler/algorithm"
+	pluginPred "k8s.io/kubernetes/plugin/pkg/scheduler/algorithm/predicates"
+	"k8s.io/kubernetes/plugin/pkg/scheduler/schedulercache"
+)
+
+// Quality of service classes of a pod, as computed by the kubelet
+const (
+	qosBestEffort = "BestEffort"
+	qosBurstable  = "Burstable"
+	qosGuaranteed = "Guaranteed"
+)
+
+var defaultNodeConditions = []NodeConditionPolicy{
+	{Type: api.NodeOutOfDisk},
+	{Type: api.NodeMemoryPressure, QOSClasses: []string{qosBestEffort}},
+	{Type: api.NodeDiskPressure},
+}
+
+// getNodeConditions returns the node conditions of the packing policy, or the defaults if none are set
+func getNodeConditions() []NodeConditionPolicy {
+	if packingPolicy.NodeConditions == nil {
+		return defaultNodeConditions
+	}
+	return packingPolicy.NodeConditions
+}
+
+// status returns the status of the condition, or Unknown if the condition has not been heartbeated
+// within the max age. Conditions without a heartbeat are never stale.
+func (p StaleConditionPolicy) status(c api.NodeCondition) (status api.ConditionStatus, stale bool) {
+	if p.MaxAge.Duration <= 0 || c.LastHeartbeatTime.IsZero() {
+		return c.Status, false
+	}
+	if time.Since(c.LastHeartbeatTime.Time) > p.MaxAge.Duration {
+		return api.ConditionUnknown, true
+	}
+	return c.Status, false
+}
+
+// getPodQOS returns the quality of service class of the pod. Pods without any CPU or memory
+// requests or limits are BestEffort, pods whose containers all have limits equal to their
+// requests are Guaranteed, every other pod is Burstable.
+func getPodQOS(pod *api.Pod) string {
+	bestEffort := true
+	guaranteed := len(pod.Spec.Containers) > 0
+	for _, container := range pod.Spec.Containers {
+		for _, name := range []api.ResourceName{api.ResourceCPU, api.ResourceMemory} {
+			request, requested := container.Resources.Requests[name]
+			limit, limited := container.Resources.Limits[name]
+			if (requested && !request.IsZero()) || (limited && !limit.IsZero()) {
+				bestEffort = false
+			}
+			if !limited || limit.IsZero() || (requested && request.Cmp(limit) != 0) {
+				guaranteed = false
+			}
+		}
+	}
+
+	switch {
+	case bestEffort:
+		return qosBestEffort
+	case guaranteed:
+		return qosGuaranteed
+	}
+	return qosBurstable
+}
+
+// nodeCondition is a NodeConditionPolicy ready to check nodes against
+type nodeCondition struct {
+	conditionType api.NodeConditionType
+	status        api.ConditionStatus
+	qosClasses    map[string]bool
+	failure       *pluginPred.PredicateFailureError
+	staleFailure  *pluginPred.PredicateFailureError
+}
+
+func newNodeCondition(policy NodeConditionPolicy) (*nodeCondition, error) {
+	if policy.Type == "" {
+		return nil, fmt.Errorf("Node condition policy needs a condition type")
+	}
+	if policy.Status == "" {
+		policy.Status = api.ConditionTrue
+	}
+	if len(policy.QOSClasses) == 0 {
+		policy.QOSClasses = []string{qosBestEffort, qosBurstable, qosGuaranteed}
+	}
+
+	condition := &nodeCondition{
+		conditionType: policy.Type,
+		status:        policy.Status,
+		qosClasses:    map[string]bool{},
+		failure:       newPredicateFailure(fmt.Sprintf("%s-%s", nodeConditionsPred, policy.Type)),
+		staleFailure:  newPredicateFailure(fmt.Sprintf("%s-%s-Stale", nodeConditionsPred, policy.Type)),
+	}
+	for _, class := range policy.QOSClasses {
+		switch class {
+		case qosBestEffort, qosBurstable, qosGuaranteed:
+			condition.qosClasses[class] = true
+		default:
+			return nil, fmt.Errorf("Unknown QoS class %s for node condition %s", class, policy.Type)
+		}
+	}
+	return condition, nil
+}
+
+//NewNodeConditionPredicate creates a predicate rejecting nodes that report any of the given conditions
+//for pods of the QoS classes each condition applies to. Conditions are checked as Unknown once they are
+//stale, or reject the node if the stale policy says so
+func NewNodeConditionPredicate(policies []NodeConditionPolicy, stale StaleConditionPolicy) (algorithm.FitPredicate, error) {
+	conditions := []*nodeCondition{}
+	for _, policy := range policies {
+		condition, err := newNodeCondition(policy)
+		if err != nil {
+			return nil, err
+		}
+		conditions = append(conditions, condition)
+	}
+
+	return func(pod *api.Pod, meta interface{}, cacheInfo *schedulercache.NodeInfo) (bool, []algorithm.PredicateFailureReason, error) {
+		info := cacheInfo.Node()
+		qos := getPodQOS(pod)
+
+		reasons := []algorithm.PredicateFailureReason{}
+		for _, condition := range conditions {
+			if !condition.qosClasses[qos] {
+				continue
+			}
+
+			for _, c := range info.Status.Conditions {
+				if c.Type != condition.conditionType {
+					continue
+				}
+
+				status, isStale := stale.status(c)
+				if isStale && stale.Action == StaleConditionReject {
+					glog.V(10).Infof("Cannot schedule %s Pod %s, Because Node %v last heartbeated %s at %v", qos, pod.Name, info.Name, c.Type, c.LastHeartbeatTime)
+					reasons = append(reasons, condition.staleFailure)
+				} else if status == condition.status {
+					glog.V(10).Infof("Cannot schedule %s Pod %s, Because Node %v reports %s %s", qos, pod.Name, info.Name, c.Type, status)
+					reasons = append(reasons, condition.failure)
+				}
+			}
+		}
+		return len(reasons) == 0, reasons, nil
+	}, nil
+}
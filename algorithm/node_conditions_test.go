@@ -0,0 +1,246 @@
+package algorithm
+
+import (
+	"testing"
+	"time"
+
+	"k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/api/unversioned"
+	"k8s.io/kubernetes/plugin/pkg/scheduler/schedulercache"
+)
+
+func createConditionNode(conditions ...api.NodeCondition) *api.Node {
+	return &api.Node{
+		ObjectMeta: api.ObjectMeta{Name: "machine1"},
+		Status: api.NodeStatus{
+			Conditions: conditions,
+		},
+	}
+}
+
+func TestGetPodQOS(t *testing.T) {
+	tests := []struct {
+		testName string
+		pod      *api.Pod
+		expected string
+	}{
+		{
+			testName: "NoContainers",
+			pod:      &api.Pod{},
+			expected: qosBestEffort,
+		},
+		{
+			testName: "NoResources",
+			pod:      &api.Pod{Spec: api.PodSpec{Containers: []api.Container{{}}}},
+			expected: qosBestEffort,
+		},
+		{
+			testName: "RequestsOnly",
+			pod:      createResourcePod(1000, 1000, 0),
+			expected: qosBurstable,
+		},
+		{
+			testName: "LimitsEqualRequests",
+			pod: &api.Pod{Spec: api.PodSpec{Containers: []api.Container{
+				{Resources: makeResourceRequirements(1000, 1000, 1000, 1000)},
+			}}},
+			expected: qosGuaranteed,
+		},
+		{
+			testName: "LimitsAboveRequests",
+			pod: &api.Pod{Spec: api.PodSpec{Containers: []api.Container{
+				{Resources: makeResourceRequirements(1000, 1000, 2000, 1000)},
+			}}},
+			expected: qosBurstable,
+		},
+	}
+
+	for _, test := range tests {
+		if actual := getPodQOS(test.pod); actual != test.expected {
+			t.Errorf("Test %s. Expected: %v Actual: %v", test.testName, test.expected, actual)
+		}
+	}
+}
+
+func TestNodeConditionPredicate(t *testing.T) {
+	guaranteed := &api.Pod{Spec: api.PodSpec{Containers: []api.Container{
+		{Resources: makeResourceRequirements(1000, 1000, 1000, 1000)},
+	}}}
+	bestEffort := &api.Pod{}
+
+	tests := []struct {
+		testName string
+		policies []NodeConditionPolicy
+		pod      *api.Pod
+		node     *api.Node
+		expected bool
+		reason   string
+	}{
+		{
+			testName: "Healthy",
+			policies: defaultNodeConditions,
+			pod:      bestEffort,
+			node: createConditionNode(
+				api.NodeCondition{Type: api.NodeMemoryPressure, Status: api.ConditionFalse},
+				api.NodeCondition{Type: api.NodeDiskPressure, Status: api.ConditionFalse},
+			),
+			expected: true,
+		},
+		{
+			testName: "MemoryPressureBestEffort",
+			policies: defaultNodeConditions,
+			pod:      bestEffort,
+			node:     createConditionNode(api.NodeCondition{Type: api.NodeMemoryPressure, Status: api.ConditionTrue}),
+			expected: false,
+			reason:   "NodeConditions-MemoryPressure",
+		},
+		{
+			testName: "MemoryPressureGuaranteed",
+			policies: defaultNodeConditions,
+			pod:      guaranteed,
+			node:     createConditionNode(api.NodeCondition{Type: api.NodeMemoryPressure, Status: api.ConditionTrue}),
+			expected: true,
+		},
+		{
+			testName: "DiskPressureGuaranteed",
+			policies: defaultNodeConditions,
+			pod:      guaranteed,
+			node:     createConditionNode(api.NodeCondition{Type: api.NodeDiskPressure, Status: api.ConditionTrue}),
+			expected: false,
+			reason:   "NodeConditions-DiskPressure",
+		},
+		{
+			testName: "CustomCondition",
+			policies: []NodeConditionPolicy{{Type: "KernelDeadlock"}},
+			pod:      guaranteed,
+			node:     createConditionNode(api.NodeCondition{Type: "KernelDeadlock", Status: api.ConditionTrue}),
+			expected: false,
+			reason:   "NodeConditions-KernelDeadlock",
+		},
+		{
+			testName: "ConditionStatus",
+			policies: []NodeConditionPolicy{{Type: api.NodeReady, Status: api.ConditionFalse}},
+			pod:      guaranteed,
+			node:     createConditionNode(api.NodeCondition{Type: api.NodeReady, Status: api.ConditionFalse}),
+			expected: false,
+			reason:   "NodeConditions-Ready",
+		},
+		{
+			testName: "ConditionNotListed",
+			policies: []NodeConditionPolicy{{Type: api.NodeNetworkUnavailable}},
+			pod:      guaranteed,
+			node:     createConditionNode(api.NodeCondition{Type: api.NodeDiskPressure, Status: api.ConditionTrue}),
+			expected: true,
+		},
+	}
+
+	for _, test := range tests {
+		predicate, err := NewNodeConditionPredicate(test.policies, StaleConditionPolicy{Action: StaleConditionTolerate})
+		if err != nil {
+			t.Fatalf("Test %s had error creating the predicate %v", test.testName, err)
+		}
+
+		info := schedulercache.NewNodeInfo()
+		info.SetNode(test.node)
+		actual, reasons, err := predicate(test.pod, nil, info)
+		if err != nil {
+			t.Errorf("Test %s had error %v", test.testName, err)
+		}
+
+		if actual != test.expected {
+			t.Errorf("Test %s. Expected: %v Actual: %v", test.testName, test.expected, actual)
+		}
+		if !test.expected && !hasReason(reasons, test.reason) {
+			t.Errorf("Test %s. Expected reason %s in %v", test.testName, test.reason, reasons)
+		}
+	}
+}
+
+func TestNodeConditionPredicateStale(t *testing.T) {
+	stale := unversioned.NewTime(time.Now().Add(-time.Hour))
+	fresh := unversioned.NewTime(time.Now())
+	maxAge := unversioned.Duration{Duration: 5 * time.Minute}
+
+	tests := []struct {
+		testName string
+		policies []NodeConditionPolicy
+		stale    StaleConditionPolicy
+		node     *api.Node
+		expected bool
+		reason   string
+	}{
+		{
+			testName: "FreshPressure",
+			policies: defaultNodeConditions,
+			stale:    StaleConditionPolicy{MaxAge: maxAge, Action: StaleConditionTolerate},
+			node:     createConditionNode(api.NodeCondition{Type: api.NodeDiskPressure, Status: api.ConditionTrue, LastHeartbeatTime: fresh}),
+			expected: false,
+			reason:   "NodeConditions-DiskPressure",
+		},
+		{
+			testName: "StalePressureTolerated",
+			policies: defaultNodeConditions,
+			stale:    StaleConditionPolicy{MaxAge: maxAge, Action: StaleConditionTolerate},
+			node:     createConditionNode(api.NodeCondition{Type: api.NodeDiskPressure, Status: api.ConditionTrue, LastHeartbeatTime: stale}),
+			expected: true,
+		},
+		{
+			testName: "StalePressureNoMaxAge",
+			policies: defaultNodeConditions,
+			stale:    StaleConditionPolicy{Action: StaleConditionReject},
+			node:     createConditionNode(api.NodeCondition{Type: api.NodeDiskPressure, Status: api.ConditionTrue, LastHeartbeatTime: stale}),
+			expected: false,
+			reason:   "NodeConditions-DiskPressure",
+		},
+		{
+			testName: "StaleRejected",
+			policies: defaultNodeConditions,
+			stale:    StaleConditionPolicy{MaxAge: maxAge, Action: StaleConditionReject},
+			node:     createConditionNode(api.NodeCondition{Type: api.NodeDiskPressure, Status: api.ConditionFalse, LastHeartbeatTime: stale}),
+			expected: false,
+			reason:   "NodeConditions-DiskPressure-Stale",
+		},
+		{
+			testName: "StaleUnknownPolicy",
+			policies: []NodeConditionPolicy{{Type: api.NodeReady, Status: api.ConditionUnknown}},
+			stale:    StaleConditionPolicy{MaxAge: maxAge, Action: StaleConditionTolerate},
+			node:     createConditionNode(api.NodeCondition{Type: api.NodeReady, Status: api.ConditionTrue, LastHeartbeatTime: stale}),
+			expected: false,
+			reason:   "NodeConditions-Ready",
+		},
+	}
+
+	for _, test := range tests {
+		predicate, err := NewNodeConditionPredicate(test.policies, test.stale)
+		if err != nil {
+			t.Fatalf("Test %s had error creating the predicate %v", test.testName, err)
+		}
+
+		info := schedulercache.NewNodeInfo()
+		info.SetNode(test.node)
+		actual, reasons, err := predicate(&api.Pod{}, nil, info)
+		if err != nil {
+			t.Errorf("Test %s had error %v", test.testName, err)
+		}
+
+		if actual != test.expected {
+			t.Errorf("Test %s. Expected: %v Actual: %v", test.testName, test.expected, actual)
+		}
+		if !test.expected && !hasReason(reasons, test.reason) {
+			t.Errorf("Test %s. Expected reason %s in %v", test.testName, test.reason, reasons)
+		}
+	}
+}
+
+func TestNewNodeConditionPredicateInvalid(t *testing.T) {
+	policies := [][]NodeConditionPolicy{
+		{{}},
+		{{Type: api.NodeMemoryPressure, QOSClasses: []string{"Premium"}}},
+	}
+
+	for _, policy := range policies {
+		if _, err := NewNodeConditionPredicate(policy, StaleConditionPolicy{}); err == nil {
+			t.Errorf("Expected an error for %v", policy)
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package algorithm
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/golang/glog"
+	"k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/plugin/pkg/scheduler/schedulercache"
+)
+
+// Annotation with the priority of a pod. Higher priority pods may reclaim the resources of lower priority pods
+// by evicting them, which PreemptionCandidates reports
+const podPriorityAnnotation = "packscheduler.alpha.kubernetes.io/priority"
+
+//PreemptionCandidate is a node a pod only fits on once the listed lower priority pods are evicted
+type PreemptionCandidate struct {
+	Node string `json:"node"`
+	//Pods are the pods to evict as namespace/name, lowest priority first
+	Pods []string `json:"pods"`
+}
+
+// getPodPriority returns the priority of the pod from its annotation, or the default of the packing policy
+// when it has none or its namespace may not set one
+func getPodPriority(pod *api.Pod) int {
+	value, exists := pod.Annotations[podPriorityAnnotation]
+	if !exists || !packingPolicy.PodPriority.allowed(pod.Namespace) {
+		return packingPolicy.PodPriority.Default
+	}
+
+	priority, err := strconv.Atoi(value)
+	if err != nil {
+		glog.V(2).Infof("Ignoring invalid priority %q of Pod %s/%s", value, pod.Namespace, pod.Name)
+		return packingPolicy.PodPriority.Default
+	}
+	return priority
+}
+
+// allowed returns true when the pods of the namespace may set their priority
+func (p PodPriorityPolicy) allowed(namespace string) bool {
+	for _, allowed := range p.Namespaces {
+		if allowed == namespace {
+			return true
+		}
+	}
+	return false
+}
+
+// splitReclaimablePods splits the pods into those of a lower priority than 'pod', whose resources it may reclaim,
+// and those it has to fit next to
+func splitReclaimablePods(pod *api.Pod, pods []*api.Pod) (reclaimable, kept []*api.Pod) {
+	priority := getPodPriority(pod)
+	for _, p := range pods {
+		if getPodPriority(p) < priority {
+			reclaimable = append(reclaimable, p)
+		} else {
+			kept = append(kept, p)
+		}
+	}
+	return reclaimable, kept
+}
+
+// getPreemptionVictims returns the fewest lower priority pods to evict for the pod to fit on the node without
+// overcommitting it, lowest priority first. Pods are evicted lowest priority and largest first, then any
+// eviction that turns out not to be needed is undone, highest priority first. 'ok' is false when the pod
+// does not fit even with every lower priority pod evicted
+func getPreemptionVictims(pod *api.Pod, node *api.Node, pods []*api.Pod) (victims []*api.Pod, ok bool) {
+	reclaimable, kept := splitReclaimablePods(pod, pods)
+	if checkOverCommit(pod, node, kept) != nil {
+		return nil, false
+	}
+	sort.Sort(podsByPriority{pods: reclaimable, node: node})
+
+	remaining := append(append([]*api.Pod{}, kept...), reclaimable...)
+	for len(victims) < len(reclaimable) && checkOverCommit(pod, node, remaining) != nil {
+		victims = append(victims, reclaimable[len(victims)])
+		remaining = append(append([]*api.Pod{}, kept...), reclaimable[len(victims):]...)
+	}
+
+	for i := len(victims) - 1; i >= 0; i-- {
+		spared := append(append([]*api.Pod{}, victims[:i]...), victims[i+1:]...)
+		if checkOverCommit(pod, node, withoutPods(pods, spared)) == nil {
+			victims = spared
+		}
+	}
+	return victims, true
+}
+
+//PreemptionCandidates returns the nodes the pod only fits on by evicting lower priority pods, with the pods
+//to evict. Nodes needing the fewest evictions come first. 'pods' are the pods already assigned to the nodes
+func PreemptionCandidates(pod *api.Pod, nodes []*api.Node, pods []*api.Pod) []PreemptionCandidate {
+	nodeNameToInfo := schedulercache.CreateNodeNameToInfoMap(pods, nodes)
+
+	candidates := []PreemptionCandidate{}
+	for _, node := range nodes {
+		victims, ok := getPreemptionVictims(pod, node, nodeNameToInfo[node.Name].Pods())
+		if !ok || len(victims) == 0 {
+			continue
+		}
+
+		candidate := PreemptionCandidate{Node: node.Name, Pods: []string{}}
+		for _, victim := range victims {
+			candidate.Pods = append(candidate.Pods, fmt.Sprintf("%s/%s", victim.Namespace, victim.Name))
+		}
+		candidates = append(candidates, candidate)
+	}
+
+	sort.Sort(byVictims(candidates))
+	return candidates
+}
+
+// withoutPods returns the pods that are not in 'removed'
+func withoutPods(pods, removed []*api.Pod) []*api.Pod {
+	left := []*api.Pod{}
+	for _, pod := range pods {
+		found := false
+		for _, r := range removed {
+			if pod == r {
+				found = true
+				break
+			}
+		}
+		if !found {
+			left = append(left, pod)
+		}
+	}
+	return left
+}
+
+// podsByPriority sorts pods lowest priority first, then by the resources they request on a node, largest first
+type podsByPriority struct {
+	pods []*api.Pod
+	node *api.Node
+}
+
+func (p podsByPriority) Len() int { return len(p.pods) }
+func (p podsByPriority) Less(i, j int) bool {
+	if first, second := getPodPriority(p.pods[i]), getPodPriority(p.pods[j]); first != second {
+		return first < second
+	}
+	return podsByRequest{pods: p.pods, node: p.node}.Less(i, j)
+}
+func (p podsByPriority) Swap(i, j int) { p.pods[i], p.pods[j] = p.pods[j], p.pods[i] }
+
+type byVictims []PreemptionCandidate
+
+func (c byVictims) Len() int { return len(c) }
+func (c byVictims) Less(i, j int) bool {
+	if len(c[i].Pods) != len(c[j].Pods) {
+		return len(c[i].Pods) < len(c[j].Pods)
+	}
+	return c[i].Node < c[j].Node
+}
+func (c byVictims) Swap(i, j int) { c[i], c[j] = c[j], c[i] }
@@ -0,0 +1,103 @@
+package algorithm
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/plugin/pkg/scheduler/schedulercache"
+)
+
+func createPriorityPod(name, node, priority string, milliCPU int64) *api.Pod {
+	pod := createSimulatedPod(name, milliCPU, 100)
+	pod.Spec.NodeName = node
+	if priority != "" {
+		pod.Annotations = map[string]string{podPriorityAnnotation: priority}
+	}
+	return pod
+}
+
+func TestGetPodPriority(t *testing.T) {
+	defer func(policy PodPriorityPolicy) { packingPolicy.PodPriority = policy }(packingPolicy.PodPriority)
+	packingPolicy.PodPriority = PodPriorityPolicy{Default: 5, Namespaces: []string{"default"}}
+
+	tests := []struct {
+		testName string
+		pod      *api.Pod
+		priority int
+	}{
+		{"Annotated", createPriorityPod("pod", "", "10", 1000), 10},
+		{"Default", createPriorityPod("pod", "", "", 1000), 5},
+		{"InvalidPriority", createPriorityPod("pod", "", "urgent", 1000), 5},
+		{"NamespaceNotAllowed", inNamespace(createPriorityPod("pod", "", "10", 1000), "team-a"), 5},
+	}
+
+	for _, test := range tests {
+		if priority := getPodPriority(test.pod); priority != test.priority {
+			t.Errorf("Test %s. Expected priority %d, got %d", test.testName, test.priority, priority)
+		}
+	}
+}
+
+func TestPodOverCommitNodeReclaimsLowerPriority(t *testing.T) {
+	defer func(policy PodPriorityPolicy) { packingPolicy.PodPriority = policy }(packingPolicy.PodPriority)
+
+	tests := []struct {
+		testName string
+		policy   PodPriorityPolicy
+		existing *api.Pod
+		fits     bool
+	}{
+		{"LowerPriority", PodPriorityPolicy{Namespaces: []string{"default"}}, createPriorityPod("low", "machine1", "", 3000), true},
+		{"SamePriority", PodPriorityPolicy{Namespaces: []string{"default"}}, createPriorityPod("same", "machine1", "10", 3000), false},
+		{"PrioritiesOff", PodPriorityPolicy{}, createPriorityPod("low", "machine1", "", 3000), false},
+	}
+
+	for _, test := range tests {
+		packingPolicy.PodPriority = test.policy
+		info := schedulercache.NewNodeInfo(test.existing)
+		info.SetNode(createResourceNode(4000, 10000, 0, 10))
+
+		fits, _, err := PodOverCommitNode(createPriorityPod("pod", "", "10", 2000), nil, info)
+		if err != nil {
+			t.Errorf("Test %s. Unexpected error %v", test.testName, err)
+			continue
+		}
+		if fits != test.fits {
+			t.Errorf("Test %s. Expected fit %t, got %t", test.testName, test.fits, fits)
+		}
+	}
+}
+
+func TestPreemptionCandidates(t *testing.T) {
+	defer func(policy PodPriorityPolicy) { packingPolicy.PodPriority = policy }(packingPolicy.PodPriority)
+	packingPolicy.PodPriority = PodPriorityPolicy{Namespaces: []string{"default"}}
+
+	nodes := []*api.Node{
+		createSimulatedNode("node-a", 4000, 10000),
+		createSimulatedNode("node-b", 4000, 10000),
+		createSimulatedNode("node-c", 4000, 10000),
+		createSimulatedNode("node-d", 4000, 10000),
+	}
+	pods := []*api.Pod{
+		createPriorityPod("low-1", "node-a", "1", 1000),
+		createPriorityPod("low-0", "node-a", "", 2500),
+		createPriorityPod("high", "node-a", "20", 500),
+		createPriorityPod("small", "node-b", "", 1000),
+		createPriorityPod("big", "node-b", "1", 3000),
+		createPriorityPod("fits", "node-c", "", 1000),
+		createPriorityPod("critical", "node-d", "50", 3000),
+	}
+
+	candidates := PreemptionCandidates(createPriorityPod("pod", "", "10", 2000), nodes, pods)
+
+	// node-a evicts the lowest priority pod, node-b spares the small pod once the big one is evicted,
+	// node-c fits without evicting and node-d does not fit at all
+	expected := []PreemptionCandidate{
+		{Node: "node-a", Pods: []string{"default/low-0"}},
+		{Node: "node-b", Pods: []string{"default/big"}},
+	}
+	if !reflect.DeepEqual(expected, candidates) {
+		t.Errorf("Expected %+v, got %+v", expected, candidates)
+	}
+}
@@ -5,7 +5,6 @@ import (
 
 	"github.com/golang/glog"
 	"k8s.io/kubernetes/pkg/api"
-	"k8s.io/kubernetes/pkg/labels"
 	"k8s.io/kubernetes/plugin/pkg/scheduler/algorithm"
 	pluginPred "k8s.io/kubernetes/plugin/pkg/scheduler/algorithm/predicates"
 	"k8s.io/kubernetes/plugin/pkg/scheduler/factory"
@@ -16,35 +15,141 @@ const (
 	nodeOutOfDiskPred     = "NodeOutOfDisk"
 	podOverCommitNodePred = "PodOverCommitNode"
 	deisUniqueAppPred     = "DeisUniqueApp"
+	uniqueAppPred         = "UniqueApp"
+	appTopologySpreadPred = "AppTopologySpread"
+	nodeConditionsPred    = "NodeConditions"
+	avoidScaleDownPred    = "AvoidScaleDown"
+	spotNodePred          = "SpotNode"
+
+	overCommitRatiosAnnotation = "packscheduler.alpha.kubernetes.io/overcommit-ratios"
 )
 
 var (
 	nodeOutOfDiskPredError        = newPredicateFailure(nodeOutOfDiskPred)
+	nodeOutOfDiskStalePredError   = newPredicateFailure(fmt.Sprintf("%s-Stale", nodeOutOfDiskPred))
 	podOverCommitNodePredError    = newPredicateFailure(podOverCommitNodePred)
 	podOverCommitNodePredCPUError = newPredicateFailure(fmt.Sprintf("%s-CPU", podOverCommitNodePred))
 	podOverCommitNodePredMemError = newPredicateFailure(fmt.Sprintf("%s-Mem", podOverCommitNodePred))
 	deisUniqueAppPredError        = newPredicateFailure(deisUniqueAppPred)
+	avoidScaleDownPredError       = newPredicateFailure(avoidScaleDownPred)
+	spotNodePredError             = newPredicateFailure(spotNodePred)
 )
 
 func newPredicateFailure(predicateName string) *pluginPred.PredicateFailureError {
 	return &pluginPred.PredicateFailureError{PredicateName: predicateName}
 }
 
+// overCommitFailure reports how far a node would be over its overcommit ratio for a resource
+type overCommitFailure struct {
+	*pluginPred.PredicateFailureError
+	requested int64
+	capacity  int64
+	ratio     float64
+}
+
+func newOverCommitFailure(name api.ResourceName, requested, capacity int64, ratio float64) *overCommitFailure {
+	failure := &overCommitFailure{
+		requested: requested,
+		capacity:  capacity,
+		ratio:     ratio,
+	}
+
+	switch name {
+	case api.ResourceCPU:
+		failure.PredicateFailureError = podOverCommitNodePredCPUError
+	case api.ResourceMemory:
+		failure.PredicateFailureError = podOverCommitNodePredMemError
+	default:
+		failure.PredicateFailureError = newPredicateFailure(fmt.Sprintf("%s-%s", podOverCommitNodePred, name))
+	}
+	return failure
+}
+
+func (e *overCommitFailure) GetReason() string {
+	allowed := int64(float64(e.capacity) * e.ratio)
+	if allowed == 0 {
+		return fmt.Sprintf("%s: requested %d but node has no capacity", e.PredicateName, e.requested)
+	}
+
+	over := float64(e.requested-allowed) * 100 / float64(allowed)
+	return fmt.Sprintf("%s: requested %d is %.1f%% over the %d allowed by a %gx overcommit of %d",
+		e.PredicateName, e.requested, over, allowed, e.ratio, e.capacity)
+}
+
+// getOverCommitRatios returns the overcommit ratios of the packing policy with any
+// overrides from the node annotation applied
+func getOverCommitRatios(node *api.Node) ResourceRatios {
+	ratios := ResourceRatios{}
+	for name, ratio := range packingPolicy.OverCommitRatios {
+		ratios[name] = ratio
+	}
+
+	value, exists := node.Annotations[overCommitRatiosAnnotation]
+	if !exists {
+		return ratios
+	}
+
+	overrides, err := parseResourceRatios(value)
+	if err != nil {
+		glog.Warningf("Ignoring invalid %s annotation on Node %s: %v", overCommitRatiosAnnotation, node.Name, err)
+		return ratios
+	}
+	for name, ratio := range overrides {
+		ratios[name] = ratio
+	}
+	return ratios
+}
+
 func init() {
-	factory.RegisterFitPredicate(
+	registerFitPredicate(
 		podOverCommitNodePred,
 		PodOverCommitNode,
 	)
 
-	factory.RegisterFitPredicate(
+	registerFitPredicate(
 		nodeOutOfDiskPred,
 		NodeOutOfDisk,
 	)
 
-	factory.RegisterFitPredicate(deisUniqueAppPred, UniqueDeisApp)
+	registerFitPredicateFactory(nodeConditionsPred, func(args factory.PluginFactoryArgs) algorithm.FitPredicate {
+		predicate, err := NewNodeConditionPredicate(getNodeConditions(), packingPolicy.StaleConditions)
+		if err != nil {
+			glog.Fatalf("Invalid node condition policy: %v", err)
+		}
+		return predicate
+	})
+
+	registerFitPredicate(deisUniqueAppPred, UniqueDeisApp)
+
+	registerFitPredicateFactory(uniqueAppPred, func(args factory.PluginFactoryArgs) algorithm.FitPredicate {
+		predicate, err := NewUniqueAppPredicate(packingPolicy.UniqueApps)
+		if err != nil {
+			glog.Fatalf("Invalid unique app policy: %v", err)
+		}
+		return predicate
+	})
+
+	registerFitPredicateFactory(appTopologySpreadPred, func(args factory.PluginFactoryArgs) algorithm.FitPredicate {
+		predicate, err := NewAppTopologySpreadPredicate(packingPolicy.AppSpreading, args.PodLister, args.NodeInfo)
+		if err != nil {
+			glog.Fatalf("Invalid app spreading policy: %v", err)
+		}
+		return predicate
+	})
+
+	registerFitPredicate(avoidScaleDownPred, AvoidScaleDown)
+
+	registerFitPredicateFactory(spotNodePred, func(args factory.PluginFactoryArgs) algorithm.FitPredicate {
+		predicate, err := NewSpotNodePredicate(packingPolicy.Spot)
+		if err != nil {
+			glog.Fatalf("Invalid spot policy: %v", err)
+		}
+		return predicate
+	})
 }
 
 //NodeOutOfDisk determine if a node is reporting out of disk.
+//A stale condition is treated as Unknown, or rejects the node if the stale policy says so
 func NodeOutOfDisk(pod *api.Pod, meta interface{}, cacheInfo *schedulercache.NodeInfo) (bool, []algorithm.PredicateFailureReason, error) {
 	info := cacheInfo.Node()
 
@@ -53,7 +158,13 @@ func NodeOutOfDisk(pod *api.Pod, meta interface{}, cacheInfo *schedulercache.Nod
 			continue
 		}
 
-		if c.Status == api.ConditionTrue {
+		status, stale := packingPolicy.StaleConditions.status(c)
+		if stale && packingPolicy.StaleConditions.Action == StaleConditionReject {
+			glog.V(10).Infof("Cannot schedule Pod %s, Because Node %v last heartbeated %s at %v", pod.Name, info.Name, c.Type, c.LastHeartbeatTime)
+			return false, []algorithm.PredicateFailureReason{nodeOutOfDiskStalePredError}, nil
+		}
+
+		if status == api.ConditionTrue {
 			return false, []algorithm.PredicateFailureReason{nodeOutOfDiskPredError}, nil
 		}
 	}
@@ -62,48 +173,49 @@ func NodeOutOfDisk(pod *api.Pod, meta interface{}, cacheInfo *schedulercache.Nod
 }
 
 //PodOverCommitNode determines if pod resource request/limits would cause overcommit for a node
+//beyond the overcommit ratio allowed for each resource. Pods of a lower priority than the pod are
+//not counted, as their resources can be reclaimed by evicting them
 func PodOverCommitNode(pod *api.Pod, meta interface{}, cacheInfo *schedulercache.NodeInfo) (bool, []algorithm.PredicateFailureReason, error) {
-	info := cacheInfo.Node()
+	_, kept := splitReclaimablePods(pod, cacheInfo.Pods())
+	if reason := checkOverCommit(pod, cacheInfo.Node(), kept); reason != nil {
+		return false, []algorithm.PredicateFailureReason{reason}, nil
+	}
 
-	pods := append(cacheInfo.Pods(), pod)
-	totalCPU := int64(0)
-	totalMem := int64(0)
+	return true, nil, nil
+}
+
+// checkOverCommit returns why adding the pod to the 'pods' on the node would overcommit it, or nil if it fits
+func checkOverCommit(pod *api.Pod, info *api.Node, pods []*api.Pod) algorithm.PredicateFailureReason {
+	pods = append(append([]*api.Pod{}, pods...), pod)
+	total := resourceList{}
 
-	if int64(len(pods)) > info.Status.Capacity.Pods().Value() {
+	if int64(len(pods)) > getCapacity(api.ResourcePods, info) {
 		glog.V(10).Infof("Cannot schedule Pod %s, Because Node %v would exceed Pod capacity", pod.Name, info.Name)
-		return false, []algorithm.PredicateFailureReason{podOverCommitNodePredError}, nil
+		return podOverCommitNodePredError
 	}
 
 	for _, p := range pods {
-		cpu, mem := getResourcesForPod(p)
-		totalCPU += cpu
-		totalMem += mem
+		total.add(getResourcesForPod(p, info))
+	}
 
-		if totalCPU > info.Status.Capacity.Cpu().MilliValue() {
-			glog.V(10).Infof("Cannot schedule Pod %s, Because Node %v would be overcommited on CPU", pod.Name, info.Name)
-			return false, []algorithm.PredicateFailureReason{podOverCommitNodePredCPUError}, nil //TODO return newOverCommitError("CPU") when InsufficentResources can be modified
-		}
-		if totalMem > info.Status.Capacity.Memory().Value() {
-			glog.V(10).Infof("Cannot schedule Pod %s, Because Node %v would be overcommited on Memory", pod.Name, info.Name)
-			return false, []algorithm.PredicateFailureReason{podOverCommitNodePredMemError}, nil //TODO return newOverCommitError("Memory") when InsufficentResources can be modified
+	ratios := getOverCommitRatios(info)
+	for _, name := range total.names() {
+		capacity := getCapacity(name, info)
+		ratio := ratios.ratio(name)
+		if float64(total[name]) > float64(capacity)*ratio {
+			glog.V(10).Infof("Cannot schedule Pod %s, Because Node %v would be overcommited on %s", pod.Name, info.Name, name)
+			return newOverCommitFailure(name, total[name], capacity, ratio)
 		}
 	}
 
-	return true, nil, nil
+	return nil
 }
 
-//UniqueDeisApp ensures that deis apps are unique by version on each node
+//UniqueDeisApp ensures that deis apps are unique by version on each node.
+//Only the copies in the namespace of the pod count
 func UniqueDeisApp(pod *api.Pod, meta interface{}, cacheInfo *schedulercache.NodeInfo) (bool, []algorithm.PredicateFailureReason, error) {
-	if value, exists := pod.GetLabels()["heritage"]; !exists || value != "deis" {
-		return true, nil, nil //Pod is not from deis. Move along
-	}
-
-	labelSelector := labels.SelectorFromSet(pod.Labels)
-
-	for _, p := range cacheInfo.Pods() {
-		if labelSelector.Matches(labels.Set(p.Labels)) {
-			return false, []algorithm.PredicateFailureReason{deisUniqueAppPredError}, nil
-		}
+	if !deisUniqueApp.fits(pod, cacheInfo.Pods()) {
+		return false, []algorithm.PredicateFailureReason{deisUniqueAppPredError}, nil
 	}
 
 	return true, nil, nil
@@ -2,9 +2,14 @@ package algorithm
 
 import (
 	"fmt"
+	"strings"
 	"testing"
+	"time"
 
 	"k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/api/resource"
+	"k8s.io/kubernetes/pkg/api/unversioned"
+	"k8s.io/kubernetes/plugin/pkg/scheduler/algorithm"
 	"k8s.io/kubernetes/plugin/pkg/scheduler/algorithm/predicates"
 	"k8s.io/kubernetes/plugin/pkg/scheduler/schedulercache"
 )
@@ -82,6 +87,67 @@ func TestNodeDisk(t *testing.T) {
 	}
 }
 
+func TestNodeDiskStale(t *testing.T) {
+	defer func(stale StaleConditionPolicy) { packingPolicy.StaleConditions = stale }(packingPolicy.StaleConditions)
+
+	tests := []struct {
+		testName  string
+		status    api.ConditionStatus
+		heartbeat time.Duration
+		action    StaleConditionAction
+		expected  bool
+	}{
+		{
+			testName:  "FreshFull",
+			status:    api.ConditionTrue,
+			heartbeat: time.Second,
+			action:    StaleConditionTolerate,
+			expected:  false,
+		},
+		{
+			testName:  "StaleFullTolerated",
+			status:    api.ConditionTrue,
+			heartbeat: time.Hour,
+			action:    StaleConditionTolerate,
+			expected:  true,
+		},
+		{
+			testName:  "StaleFineRejected",
+			status:    api.ConditionFalse,
+			heartbeat: time.Hour,
+			action:    StaleConditionReject,
+			expected:  false,
+		},
+		{
+			testName:  "FreshFineRejectMode",
+			status:    api.ConditionFalse,
+			heartbeat: time.Second,
+			action:    StaleConditionReject,
+			expected:  true,
+		},
+	}
+
+	for _, test := range tests {
+		packingPolicy.StaleConditions = StaleConditionPolicy{
+			MaxAge: unversioned.Duration{Duration: 5 * time.Minute},
+			Action: test.action,
+		}
+
+		node := createDiskNode(NodeDiskFine, test.status)
+		node.Status.Conditions[0].LastHeartbeatTime = unversioned.NewTime(time.Now().Add(-test.heartbeat))
+		info := schedulercache.NewNodeInfo()
+		info.SetNode(node)
+		actual, _, err := NodeOutOfDisk(&api.Pod{}, nil, info)
+		if err != nil {
+			t.Errorf("Test %s had error %v", test.testName, err)
+		}
+
+		if actual != test.expected {
+			t.Errorf("Test %s. Expected: %v Actual: %v", test.testName, test.expected, actual)
+		}
+	}
+}
+
 func createDeisPod(version string) *api.Pod {
 	return &api.Pod{
 		ObjectMeta: api.ObjectMeta{
@@ -139,3 +205,236 @@ func TestDeisPredicate(t *testing.T) {
 		}
 	}
 }
+
+func createResourcePod(milliCPU, memory, gpus int64) *api.Pod {
+	pod := &api.Pod{
+		Spec: api.PodSpec{
+			Containers: []api.Container{
+				{
+					Resources: makeResourceRequirements(milliCPU, memory, 0, 0),
+				},
+			},
+		},
+	}
+	if gpus > 0 {
+		pod.Spec.Containers[0].Resources.Requests[api.ResourceNvidiaGPU] = *resource.NewQuantity(gpus, resource.DecimalSI)
+	}
+	return pod
+}
+
+func createResourceNode(milliCPU, memory, gpus, pods int64) *api.Node {
+	node := makeNode("machine1", milliCPU, memory)
+	node.Status.Capacity[api.ResourceNvidiaGPU] = *resource.NewQuantity(gpus, resource.DecimalSI)
+	node.Status.Capacity[api.ResourcePods] = *resource.NewQuantity(pods, resource.DecimalSI)
+	return node
+}
+
+func TestPodOverCommitNode(t *testing.T) {
+	tests := []struct {
+		testName string
+		pod      *api.Pod
+		existing []*api.Pod
+		node     *api.Node
+		expected bool
+		reason   string
+	}{
+		{
+			testName: "Fits",
+			pod:      createResourcePod(1000, 1000, 0),
+			existing: []*api.Pod{createResourcePod(1000, 1000, 0)},
+			node:     createResourceNode(2000, 2000, 0, 10),
+			expected: true,
+		},
+		{
+			testName: "TooManyPods",
+			pod:      createResourcePod(1000, 1000, 0),
+			existing: []*api.Pod{createResourcePod(1000, 1000, 0)},
+			node:     createResourceNode(2000, 2000, 0, 1),
+			expected: false,
+			reason:   podOverCommitNodePred,
+		},
+		{
+			testName: "CPU",
+			pod:      createResourcePod(1500, 1000, 0),
+			existing: []*api.Pod{createResourcePod(1000, 1000, 0)},
+			node:     createResourceNode(2000, 2000, 0, 10),
+			expected: false,
+			reason:   "PodOverCommitNode-CPU",
+		},
+		{
+			testName: "Memory",
+			pod:      createResourcePod(1000, 1500, 0),
+			existing: []*api.Pod{createResourcePod(1000, 1000, 0)},
+			node:     createResourceNode(2000, 2000, 0, 10),
+			expected: false,
+			reason:   "PodOverCommitNode-Mem",
+		},
+		{
+			testName: "GPUFits",
+			pod:      createResourcePod(1000, 1000, 1),
+			existing: []*api.Pod{createResourcePod(1000, 1000, 1)},
+			node:     createResourceNode(2000, 2000, 2, 10),
+			expected: true,
+		},
+		{
+			testName: "GPU",
+			pod:      createResourcePod(1000, 1000, 1),
+			existing: []*api.Pod{createResourcePod(1000, 1000, 1)},
+			node:     createResourceNode(2000, 2000, 1, 10),
+			expected: false,
+			reason:   "PodOverCommitNode-alpha.kubernetes.io/nvidia-gpu",
+		},
+	}
+
+	for _, test := range tests {
+		info := schedulercache.NewNodeInfo(test.existing...)
+		info.SetNode(test.node)
+		actual, reasons, err := PodOverCommitNode(test.pod, nil, info)
+		if err != nil {
+			t.Errorf("Test %s had error %v", test.testName, err)
+		}
+
+		if actual != test.expected {
+			t.Errorf("Test %s. Expected: %v Actual: %v", test.testName, test.expected, actual)
+		}
+		if !test.expected && !hasReason(reasons, test.reason) {
+			t.Errorf("Test %s. Expected reason %s in %v", test.testName, test.reason, reasons)
+		}
+	}
+}
+
+func hasReason(reasons []algorithm.PredicateFailureReason, reason string) bool {
+	for _, r := range reasons {
+		if strings.HasPrefix(r.GetReason(), reason) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestPodOverCommitNodeAllocatable(t *testing.T) {
+	defer func(useAllocatable bool) { packingPolicy.UseAllocatable = useAllocatable }(packingPolicy.UseAllocatable)
+
+	tests := []struct {
+		testName       string
+		allocatable    api.ResourceList
+		useAllocatable bool
+		expected       bool
+	}{
+		{
+			testName:       "CapacityOnly",
+			useAllocatable: true,
+			expected:       true,
+		},
+		{
+			testName: "AllocatableTooSmall",
+			allocatable: api.ResourceList{
+				api.ResourceCPU: *resource.NewMilliQuantity(1500, resource.DecimalSI),
+			},
+			useAllocatable: true,
+			expected:       false,
+		},
+		{
+			testName: "AllocatableIgnored",
+			allocatable: api.ResourceList{
+				api.ResourceCPU: *resource.NewMilliQuantity(1500, resource.DecimalSI),
+			},
+			useAllocatable: false,
+			expected:       true,
+		},
+		{
+			testName: "AllocatableMemoryFallsBackToCapacity",
+			allocatable: api.ResourceList{
+				api.ResourceCPU: *resource.NewMilliQuantity(2000, resource.DecimalSI),
+			},
+			useAllocatable: true,
+			expected:       true,
+		},
+	}
+
+	for _, test := range tests {
+		packingPolicy.UseAllocatable = test.useAllocatable
+		node := createResourceNode(2000, 2000, 0, 10)
+		node.Status.Allocatable = test.allocatable
+		info := schedulercache.NewNodeInfo(createResourcePod(1000, 1000, 0))
+		info.SetNode(node)
+
+		actual, _, err := PodOverCommitNode(createResourcePod(1000, 1000, 0), nil, info)
+		if err != nil {
+			t.Errorf("Test %s had error %v", test.testName, err)
+		}
+
+		if actual != test.expected {
+			t.Errorf("Test %s. Expected: %v Actual: %v", test.testName, test.expected, actual)
+		}
+	}
+}
+
+func TestPodOverCommitNodeRatios(t *testing.T) {
+	defer func(ratios ResourceRatios) { packingPolicy.OverCommitRatios = ratios }(packingPolicy.OverCommitRatios)
+
+	tests := []struct {
+		testName    string
+		ratios      ResourceRatios
+		annotations map[string]string
+		pod         *api.Pod
+		expected    bool
+		reason      string
+	}{
+		{
+			testName: "NoRatio",
+			ratios:   ResourceRatios{},
+			pod:      createResourcePod(2000, 1000, 0),
+			expected: false,
+			reason:   "PodOverCommitNode-CPU: requested 3000 is 50.0% over the 2000 allowed by a 1x overcommit of 2000",
+		},
+		{
+			testName: "GlobalRatio",
+			ratios:   ResourceRatios{api.ResourceCPU: 4},
+			pod:      createResourcePod(2000, 1000, 0),
+			expected: true,
+		},
+		{
+			testName: "GlobalRatioExceeded",
+			ratios:   ResourceRatios{api.ResourceCPU: 4, api.ResourceMemory: 1.2},
+			pod:      createResourcePod(2000, 1500, 0),
+			expected: false,
+			reason:   "PodOverCommitNode-Mem: requested 2500 is 4.2% over the 2400 allowed by a 1.2x overcommit of 2000",
+		},
+		{
+			testName:    "NodeOverride",
+			ratios:      ResourceRatios{api.ResourceCPU: 4},
+			annotations: map[string]string{overCommitRatiosAnnotation: "cpu=1"},
+			pod:         createResourcePod(2000, 1000, 0),
+			expected:    false,
+			reason:      "PodOverCommitNode-CPU",
+		},
+		{
+			testName:    "InvalidNodeOverride",
+			ratios:      ResourceRatios{api.ResourceCPU: 4},
+			annotations: map[string]string{overCommitRatiosAnnotation: "cpu=lots"},
+			pod:         createResourcePod(2000, 1000, 0),
+			expected:    true,
+		},
+	}
+
+	for _, test := range tests {
+		packingPolicy.OverCommitRatios = test.ratios
+		node := createResourceNode(2000, 2000, 0, 10)
+		node.Annotations = test.annotations
+		info := schedulercache.NewNodeInfo(createResourcePod(1000, 1000, 0))
+		info.SetNode(node)
+
+		actual, reasons, err := PodOverCommitNode(test.pod, nil, info)
+		if err != nil {
+			t.Errorf("Test %s had error %v", test.testName, err)
+		}
+
+		if actual != test.expected {
+			t.Errorf("Test %s. Expected: %v Actual: %v", test.testName, test.expected, actual)
+		}
+		if !test.expected && !hasReason(reasons, test.reason) {
+			t.Errorf("Test %s. Expected reason %s in %v", test.testName, test.reason, reasons)
+		}
+	}
+}
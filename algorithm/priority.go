@@ -5,30 +5,162 @@ import (
 
 	"github.com/golang/glog"
 	"k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/plugin/pkg/scheduler/algorithm"
 	schedulerapi "k8s.io/kubernetes/plugin/pkg/scheduler/api"
 	"k8s.io/kubernetes/plugin/pkg/scheduler/factory"
 	"k8s.io/kubernetes/plugin/pkg/scheduler/schedulercache"
 )
 
+const (
+	// Weight of a resource that is not listed in the weights given to a priority
+	defaultResourceWeight int64 = 1
+	// Highest score the scheduler expects from a priority function
+	frameworkMaxPriority = 10
+)
+
+var defaultResourceWeights = map[api.ResourceName]int64{
+	api.ResourceCPU:    1,
+	api.ResourceMemory: 1,
+}
+
+var defaultScoreConfig = ScoreConfig{MaxScore: frameworkMaxPriority}
+
 func init() {
-	factory.RegisterPriorityFunction("MostUsed", MostRequestedPriority, 1)
+	registerPriorityConfigFactory("MostUsed", factory.PriorityConfigFactory{
+		Function: func(args factory.PluginFactoryArgs) algorithm.PriorityFunction {
+			return NewWeightedMostRequestedPriority(defaultResourceWeights, packingPolicy.Scoring)
+		},
+		Weight: 1,
+	})
+	registerPriorityConfigFactory("WeightedMostUsed", factory.PriorityConfigFactory{
+		Function: func(args factory.PluginFactoryArgs) algorithm.PriorityFunction {
+			return NewWeightedMostRequestedPriority(packingPolicy.ResourceWeights, packingPolicy.Scoring)
+		},
+		Weight: 1,
+	})
+	registerPriorityConfigFactory("LeastLeftover", factory.PriorityConfigFactory{
+		Function: func(args factory.PluginFactoryArgs) algorithm.PriorityFunction {
+			return NewLeastLeftoverPriority(packingPolicy.Scoring)
+		},
+		Weight: 1,
+	})
+	registerPriorityConfigFactory("BalancedMostUsed", factory.PriorityConfigFactory{
+		Function: func(args factory.PluginFactoryArgs) algorithm.PriorityFunction {
+			return NewBalancedMostRequestedPriority(packingPolicy.Scoring)
+		},
+		Weight: 1,
+	})
+	registerPriorityConfigFactory("AppTopologySpread", factory.PriorityConfigFactory{
+		Function: func(args factory.PluginFactoryArgs) algorithm.PriorityFunction {
+			priority, err := NewAppTopologySpreadPriority(packingPolicy.AppSpreading)
+			if err != nil {
+				glog.Fatalf("Invalid app spreading policy: %v", err)
+			}
+			return priority
+		},
+		Weight: 1,
+	})
+	registerPriorityConfigFactory("CostMostUsed", factory.PriorityConfigFactory{
+		Function: func(args factory.PluginFactoryArgs) algorithm.PriorityFunction {
+			return NewCostMostRequestedPriority(packingPolicy.Cost, packingPolicy.Scoring)
+		},
+		Weight: 1,
+	})
+	registerPriorityConfigFactory("SpotMostUsed", factory.PriorityConfigFactory{
+		Function: func(args factory.PluginFactoryArgs) algorithm.PriorityFunction {
+			priority, err := NewSpotMostRequestedPriority(packingPolicy.Spot, packingPolicy.Scoring)
+			if err != nil {
+				glog.Fatalf("Invalid spot policy: %v", err)
+			}
+			return priority
+		},
+		Weight: 1,
+	})
+	registerPriorityConfigFactory("AvoidScaleDown", factory.PriorityConfigFactory{
+		Function: func(args factory.PluginFactoryArgs) algorithm.PriorityFunction {
+			return AvoidScaleDownPriority
+		},
+		Weight: 1,
+	})
 }
 
 //MostRequestedPriority determines the priority of nodes so that the highest utilization is chosen first
 func MostRequestedPriority(pod *api.Pod, nodeNameToInfo map[string]*schedulercache.NodeInfo, nodes []*api.Node) (schedulerapi.HostPriorityList, error) {
+	return mostRequestedPriority(pod, nodeNameToInfo, nodes, defaultResourceWeights, defaultScoreConfig)
+}
+
+//NewWeightedMostRequestedPriority creates a MostRequestedPriority that combines the per resource scores using the given weights
+func NewWeightedMostRequestedPriority(weights map[api.ResourceName]int64, scoring ScoreConfig) algorithm.PriorityFunction {
+	return func(pod *api.Pod, nodeNameToInfo map[string]*schedulercache.NodeInfo, nodes []*api.Node) (schedulerapi.HostPriorityList, error) {
+		return mostRequestedPriority(pod, nodeNameToInfo, nodes, weights, scoring)
+	}
+}
 
+func mostRequestedPriority(pod *api.Pod, nodeNameToInfo map[string]*schedulercache.NodeInfo, nodes []*api.Node, weights map[api.ResourceName]int64, scoring ScoreConfig) (schedulerapi.HostPriorityList, error) {
 	list := schedulerapi.HostPriorityList{}
 	for _, node := range nodes {
-		list = append(list, calculateResourceOccupancy(pod, node, nodeNameToInfo[node.Name].Pods()))
+		list = append(list, calculateResourceOccupancy(pod, node, nodeNameToInfo[node.Name].Pods(), weights, scoring.MaxScore))
+	}
+	if scoring.Normalize {
+		normalizeScores(list)
 	}
 	return list, nil
 }
 
+//NewLeastLeftoverPriority creates a priority that prefers the nodes with the least unused capacity after placement.
+//Nodes are scored by their dominant leftover resource, so a node left with a large hole in any one resource
+//scores low even when the other resources are nearly full
+func NewLeastLeftoverPriority(scoring ScoreConfig) algorithm.PriorityFunction {
+	return func(pod *api.Pod, nodeNameToInfo map[string]*schedulercache.NodeInfo, nodes []*api.Node) (schedulerapi.HostPriorityList, error) {
+		list := schedulerapi.HostPriorityList{}
+		for _, node := range nodes {
+			list = append(list, calculateLeftover(pod, node, nodeNameToInfo[node.Name].Pods(), scoring.MaxScore))
+		}
+		if scoring.Normalize {
+			normalizeScores(list)
+		}
+		return list, nil
+	}
+}
+
+//NewBalancedMostRequestedPriority creates a priority that prefers highly utilized nodes while penalizing
+//nodes whose resources would be used unevenly, so one resource is not exhausted while the others sit idle
+func NewBalancedMostRequestedPriority(scoring ScoreConfig) algorithm.PriorityFunction {
+	return func(pod *api.Pod, nodeNameToInfo map[string]*schedulercache.NodeInfo, nodes []*api.Node) (schedulerapi.HostPriorityList, error) {
+		list := schedulerapi.HostPriorityList{}
+		for _, node := range nodes {
+			list = append(list, calculateBalancedOccupancy(pod, node, nodeNameToInfo[node.Name].Pods(), scoring.MaxScore))
+		}
+		if scoring.Normalize {
+			normalizeScores(list)
+		}
+		return list, nil
+	}
+}
+
+// Scale the scores back to the 0-10 range of the scheduler. The best node always gets
+// the highest score, every other node is scaled relative to it.
+func normalizeScores(list schedulerapi.HostPriorityList) {
+	highest := 0
+	for _, hostPriority := range list {
+		if hostPriority.Score > highest {
+			highest = hostPriority.Score
+		}
+	}
+	if highest == 0 {
+		return
+	}
+
+	for i := range list {
+		list[i].Score = list[i].Score * frameworkMaxPriority / highest
+	}
+}
+
 // Copied from normal scheduler priorities.go
 
-// the unused capacity is calculated on a scale of 0-10
-// 0 being the lowest priority and 10 being the highest
-func calculateScore(requested int64, capacity int64, node string) int {
+// the unused capacity is calculated on a scale of 0-maxScore
+// 0 being the lowest priority and maxScore being the highest
+func calculateScore(requested int64, capacity int64, node string, maxScore int) int {
 	if capacity == 0 {
 		return 0
 	}
@@ -38,46 +170,139 @@ func calculateScore(requested int64, capacity int64, node string) int {
 		return 0
 	}
 
-	// Inverse of normal
-	return 11 - int(math.Ceil(float64((capacity-requested)*10)/float64(capacity)))
+	if maxScore == frameworkMaxPriority {
+		// Inverse of normal
+		return 11 - int(math.Ceil(float64((capacity-requested)*10)/float64(capacity)))
+	}
+
+	// Scores start at 1 so an empty node is not mistaken for one that cannot fit the pod
+	return 1 + int(float64(requested)*float64(maxScore-1)/float64(capacity))
+}
+
+// Combine the per resource scores into a single score using the given weights.
+// Resources without a weight use defaultResourceWeight.
+// A resource with a non-zero weight and a score of 0 makes the whole node score 0.
+func calculateWeightedScore(scores map[api.ResourceName]int, weights map[api.ResourceName]int64) int {
+	total := int64(0)
+	totalWeight := int64(0)
+	for name, score := range scores {
+		weight, exists := weights[name]
+		if !exists {
+			weight = defaultResourceWeight
+		}
+		if weight == 0 {
+			continue
+		}
+		if score == 0 {
+			return 0
+		}
+		total += int64(score) * weight
+		totalWeight += weight
+	}
+
+	if totalWeight == 0 {
+		return 0
+	}
+	return int(total / totalWeight)
 }
 
 // Calculate the resource occupancy on a node.  'node' has information about the resources on the node.
 // 'pods' is a list of pods currently scheduled on the node.
-func calculateResourceOccupancy(pod *api.Pod, node *api.Node, pods []*api.Pod) schedulerapi.HostPriority {
-	totalMilliCPU := int64(0)
-	totalMemory := int64(0)
-	capacityMilliCPU := node.Status.Capacity.Cpu().MilliValue()
-	capacityMemory := node.Status.Capacity.Memory().Value()
+// 'weights' is the relative importance of each resource in the final score.
+// 'maxScore' is the score of a fully used node.
+func calculateResourceOccupancy(pod *api.Pod, node *api.Node, pods []*api.Pod, weights map[api.ResourceName]int64, maxScore int) schedulerapi.HostPriority {
+	total := getResourcesAfterPlacement(pod, node, pods)
+
+	scores := map[api.ResourceName]int{}
+	for _, name := range total.names() {
+		capacity := getCapacity(name, node)
+		scores[name] = calculateScore(total[name], capacity, node.Name, maxScore)
+		glog.V(10).Infof(
+			"%v -> %v: Most Requested Priority, %s Absolute/Requested: %d / %d Score: %d",
+			pod.Name, node.Name, name,
+			total[name], capacity,
+			scores[name],
+		)
+	}
+
+	return schedulerapi.HostPriority{
+		Host:  node.Name,
+		Score: calculateWeightedScore(scores, weights),
+	}
+}
 
+// Calculate the resources used on 'node' once 'pod' is added to the 'pods' already on it.
+func getResourcesAfterPlacement(pod *api.Pod, node *api.Node, pods []*api.Pod) resourceList {
+	total := resourceList{}
 	for _, existingPod := range pods {
-		cpu, memory := getResourcesForPod(existingPod)
-		totalMilliCPU += cpu
-		totalMemory += memory
+		total.add(getResourcesForPod(existingPod, node))
 	}
 	// Add the resources requested by the current pod being scheduled.
 	// This also helps differentiate between differently sized, but empty, nodes.
-	for _, container := range pod.Spec.Containers {
-		cpu, memory := getResourcesForPacking(&container.Resources)
-		totalMilliCPU += cpu
-		totalMemory += memory
+	total.add(getResourcesForPod(pod, node))
+
+	return total
+}
+
+// Calculate the dominant leftover of a node after placement on a scale of 0-maxScore.
+// A node where the pod does not fit scores 0, a node left with no unused capacity scores maxScore.
+func calculateLeftover(pod *api.Pod, node *api.Node, pods []*api.Pod, maxScore int) schedulerapi.HostPriority {
+	total := getResourcesAfterPlacement(pod, node, pods)
+
+	dominant := float64(0)
+	for _, name := range total.names() {
+		capacity := getCapacity(name, node)
+		if capacity == 0 || total[name] > capacity {
+			glog.V(10).Infof("%v -> %v: Least Leftover Priority, %s does not fit: %d / %d", pod.Name, node.Name, name, total[name], capacity)
+			return schedulerapi.HostPriority{Host: node.Name, Score: 0}
+		}
+
+		leftover := float64(capacity-total[name]) / float64(capacity)
+		dominant = math.Max(dominant, leftover)
 	}
 
-	cpuScore := calculateScore(totalMilliCPU, capacityMilliCPU, node.Name)
-	memoryScore := calculateScore(totalMemory, capacityMemory, node.Name)
+	score := 1 + int((1-dominant)*float64(maxScore-1))
+	glog.V(10).Infof("%v -> %v: Least Leftover Priority, Dominant Leftover: %f Score: %d", pod.Name, node.Name, dominant, score)
+
+	return schedulerapi.HostPriority{
+		Host:  node.Name,
+		Score: score,
+	}
+}
+
+// Calculate the balanced occupancy of a node after placement on a scale of 0-maxScore.
+// The mean utilization of the resources is reduced by the spread between the most and
+// least utilized resource. A node where the pod does not fit scores 0.
+func calculateBalancedOccupancy(pod *api.Pod, node *api.Node, pods []*api.Pod, maxScore int) schedulerapi.HostPriority {
+	total := getResourcesAfterPlacement(pod, node, pods)
+
+	names := total.names()
+	sum := float64(0)
+	lowest := float64(1)
+	highest := float64(0)
+	for _, name := range names {
+		capacity := getCapacity(name, node)
+		if capacity == 0 || total[name] > capacity {
+			glog.V(10).Infof("%v -> %v: Balanced Most Requested Priority, %s does not fit: %d / %d", pod.Name, node.Name, name, total[name], capacity)
+			return schedulerapi.HostPriority{Host: node.Name, Score: 0}
+		}
+
+		utilization := float64(total[name]) / float64(capacity)
+		sum += utilization
+		lowest = math.Min(lowest, utilization)
+		highest = math.Max(highest, utilization)
+	}
+
+	mean := sum / float64(len(names))
+	imbalance := highest - lowest
+	score := 1 + int(mean*(1-imbalance)*float64(maxScore-1))
 	glog.V(10).Infof(
-		"%v -> %v: Most Requested Priority, Absolute/Requested: (%d, %d) / (%d, %d) Score: (%d, %d)",
+		"%v -> %v: Balanced Most Requested Priority, Mean: %f Imbalance: %f Score: %d",
 		pod.Name, node.Name,
-		totalMilliCPU, totalMemory,
-		capacityMilliCPU, capacityMemory,
-		cpuScore, memoryScore,
+		mean, imbalance,
+		score,
 	)
 
-	score := 0
-	if cpuScore != 0 && memoryScore != 0 {
-		score = int((cpuScore + memoryScore) / 2)
-	}
-
 	return schedulerapi.HostPriority{
 		Host:  node.Name,
 		Score: score,
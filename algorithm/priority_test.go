@@ -62,12 +62,278 @@ func TestGetResourcesForPacking(t *testing.T) {
 	}
 
 	for _, test := range tests {
-		if ac, am := getResourcesForPacking(&test.resources); ac != cpu || am != memory {
-			t.Errorf("Test: %s  Expected: (%d, %d)  Actual: (%d, %d)", test.test, cpu, memory, ac, am)
+		if actual := getResourcesForPacking(&test.resources, resourceList{}); actual[api.ResourceCPU] != cpu || actual[api.ResourceMemory] != memory {
+			t.Errorf("Test: %s  Expected: (%d, %d)  Actual: (%d, %d)", test.test, cpu, memory, actual[api.ResourceCPU], actual[api.ResourceMemory])
 		}
 	}
 }
 
+func TestGetResourcesForPackingModes(t *testing.T) {
+	defer func(mode ResourceMode, weight float64) {
+		packingPolicy.ResourceMode = mode
+		packingPolicy.LimitWeight = weight
+	}(packingPolicy.ResourceMode, packingPolicy.LimitWeight)
+
+	burstable := makeResourceRequirements(1000, 2000, 3000, 4000)
+	requestOnly := makeResourceRequirements(1000, 2000, 0, 0)
+
+	tests := []struct {
+		test        string
+		mode        ResourceMode
+		limitWeight float64
+		resources   api.ResourceRequirements
+		cpu         int64
+		memory      int64
+	}{
+		{
+			test:      "Requests",
+			mode:      ResourceModeRequests,
+			resources: burstable,
+			cpu:       1000,
+			memory:    2000,
+		},
+		{
+			test:      "Limits",
+			mode:      ResourceModeLimits,
+			resources: burstable,
+			cpu:       3000,
+			memory:    4000,
+		},
+		{
+			test:      "LimitsWithoutLimit",
+			mode:      ResourceModeLimits,
+			resources: requestOnly,
+			cpu:       1000,
+			memory:    2000,
+		},
+		{
+			test:      "Max",
+			mode:      ResourceModeMax,
+			resources: burstable,
+			cpu:       3000,
+			memory:    4000,
+		},
+		{
+			test:        "Interpolate",
+			mode:        ResourceModeInterpolate,
+			limitWeight: 0.25,
+			resources:   burstable,
+			cpu:         1500,
+			memory:      2500,
+		},
+		{
+			test:        "InterpolateWithoutLimit",
+			mode:        ResourceModeInterpolate,
+			limitWeight: 0.25,
+			resources:   requestOnly,
+			cpu:         1000,
+			memory:      2000,
+		},
+	}
+
+	for _, test := range tests {
+		packingPolicy.ResourceMode = test.mode
+		packingPolicy.LimitWeight = test.limitWeight
+		if actual := getResourcesForPacking(&test.resources, resourceList{}); actual[api.ResourceCPU] != test.cpu || actual[api.ResourceMemory] != test.memory {
+			t.Errorf("Test: %s  Expected: (%d, %d)  Actual: (%d, %d)", test.test, test.cpu, test.memory, actual[api.ResourceCPU], actual[api.ResourceMemory])
+		}
+	}
+}
+
+func TestGetResourcesForPod(t *testing.T) {
+	defer func(defaults ResourceDefaults, namespaces map[string]ResourceDefaults, mode ResourceMode) {
+		packingPolicy.DefaultRequests = defaults
+		packingPolicy.NamespaceDefaultRequests = namespaces
+		packingPolicy.ResourceMode = mode
+	}(packingPolicy.DefaultRequests, packingPolicy.NamespaceDefaultRequests, packingPolicy.ResourceMode)
+
+	makePod := func(namespace string, resources api.ResourceRequirements) *api.Pod {
+		return &api.Pod{
+			ObjectMeta: api.ObjectMeta{Namespace: namespace},
+			Spec: api.PodSpec{
+				Containers: []api.Container{{Resources: resources}},
+			},
+		}
+	}
+	node := makeNode("machine1", 8000, 16*1024*1024*1024)
+
+	tests := []struct {
+		test       string
+		defaults   ResourceDefaults
+		namespaces map[string]ResourceDefaults
+		mode       ResourceMode
+		pod        *api.Pod
+		cpu        int64
+		memory     int64
+	}{
+		{
+			test:     "BestEffortBuiltInDefaults",
+			defaults: newDefaultPackingPolicy().DefaultRequests,
+			pod:      makePod("default", api.ResourceRequirements{}),
+			cpu:      defaultMilliCPURequest,
+			memory:   defaultMemoryRequest,
+		},
+		{
+			test:     "ExplicitZeroIsKept",
+			defaults: newDefaultPackingPolicy().DefaultRequests,
+			pod:      makePod("default", makeResourceRequirements(0, 0, 0, 0)),
+			cpu:      0,
+			memory:   0,
+		},
+		{
+			test:     "RequestOnlyPackedAsDefault",
+			defaults: newDefaultPackingPolicy().DefaultRequests,
+			pod: makePod("default", api.ResourceRequirements{
+				Requests: api.ResourceList{api.ResourceCPU: resource.MustParse("100m")},
+			}),
+			cpu:    defaultMilliCPURequest,
+			memory: defaultMemoryRequest,
+		},
+		{
+			test:     "RequestOnlyRequestsMode",
+			defaults: newDefaultPackingPolicy().DefaultRequests,
+			mode:     ResourceModeRequests,
+			pod: makePod("default", api.ResourceRequirements{
+				Requests: api.ResourceList{api.ResourceCPU: resource.MustParse("100m")},
+			}),
+			cpu:    100,
+			memory: defaultMemoryRequest,
+		},
+		{
+			test: "CapacityFraction",
+			defaults: ResourceDefaults{
+				Resources: api.ResourceList{
+					api.ResourceCPU:    resource.MustParse("100m"),
+					api.ResourceMemory: resource.MustParse("1Gi"),
+				},
+				CapacityFractions: ResourceRatios{api.ResourceCPU: 0.05},
+			},
+			pod:    makePod("default", api.ResourceRequirements{}),
+			cpu:    400,
+			memory: 1024 * 1024 * 1024,
+		},
+		{
+			test:     "NamespaceOverride",
+			defaults: newDefaultPackingPolicy().DefaultRequests,
+			namespaces: map[string]ResourceDefaults{
+				"batch": {
+					Resources: api.ResourceList{api.ResourceCPU: resource.MustParse("1")},
+				},
+			},
+			pod:    makePod("batch", api.ResourceRequirements{}),
+			cpu:    1000,
+			memory: defaultMemoryRequest,
+		},
+		{
+			test:     "OtherNamespace",
+			defaults: newDefaultPackingPolicy().DefaultRequests,
+			namespaces: map[string]ResourceDefaults{
+				"batch": {
+					Resources: api.ResourceList{api.ResourceCPU: resource.MustParse("1")},
+				},
+			},
+			pod:    makePod("default", api.ResourceRequirements{}),
+			cpu:    defaultMilliCPURequest,
+			memory: defaultMemoryRequest,
+		},
+	}
+
+	for _, test := range tests {
+		packingPolicy.DefaultRequests = test.defaults
+		packingPolicy.NamespaceDefaultRequests = test.namespaces
+		packingPolicy.ResourceMode = ResourceModeMax
+		if test.mode != "" {
+			packingPolicy.ResourceMode = test.mode
+		}
+		if actual := getResourcesForPod(test.pod, node); actual[api.ResourceCPU] != test.cpu || actual[api.ResourceMemory] != test.memory {
+			t.Errorf("Test: %s  Expected: (%d, %d)  Actual: (%d, %d)", test.test, test.cpu, test.memory, actual[api.ResourceCPU], actual[api.ResourceMemory])
+		}
+	}
+}
+
+func TestGetResourcesForPodInitContainers(t *testing.T) {
+	containers := []api.Container{
+		{Resources: makeResourceRequirements(1000, 1000, 0, 0)},
+		{Resources: makeResourceRequirements(1000, 1000, 0, 0)},
+	}
+	node := makeNode("machine1", 8000, 8000)
+
+	tests := []struct {
+		test   string
+		pod    *api.Pod
+		cpu    int64
+		memory int64
+	}{
+		{
+			test: "AppContainersOnly",
+			pod: &api.Pod{
+				Spec: api.PodSpec{Containers: containers},
+			},
+			cpu:    2000,
+			memory: 2000,
+		},
+		{
+			test: "LargeInitContainer",
+			pod: &api.Pod{
+				Spec: api.PodSpec{
+					InitContainers: []api.Container{
+						{Resources: makeResourceRequirements(500, 500, 0, 0)},
+						{Resources: makeResourceRequirements(3000, 1500, 0, 0)},
+					},
+					Containers: containers,
+				},
+			},
+			cpu:    3000,
+			memory: 2000,
+		},
+		{
+			test: "Overhead",
+			pod: &api.Pod{
+				ObjectMeta: api.ObjectMeta{
+					Annotations: map[string]string{podOverheadAnnotation: "cpu=100m,memory=64"},
+				},
+				Spec: api.PodSpec{Containers: containers},
+			},
+			cpu:    2100,
+			memory: 2064,
+		},
+		{
+			test: "InvalidOverhead",
+			pod: &api.Pod{
+				ObjectMeta: api.ObjectMeta{
+					Annotations: map[string]string{podOverheadAnnotation: "cpu"},
+				},
+				Spec: api.PodSpec{Containers: containers},
+			},
+			cpu:    2000,
+			memory: 2000,
+		},
+	}
+
+	for _, test := range tests {
+		if actual := getResourcesForPod(test.pod, node); actual[api.ResourceCPU] != test.cpu || actual[api.ResourceMemory] != test.memory {
+			t.Errorf("Test: %s  Expected: (%d, %d)  Actual: (%d, %d)", test.test, test.cpu, test.memory, actual[api.ResourceCPU], actual[api.ResourceMemory])
+		}
+	}
+}
+
+func TestGetResourcesForPackingExtended(t *testing.T) {
+	resources := makeResourceRequirements(1000, 2000, 0, 0)
+	resources.Requests[api.ResourceNvidiaGPU] = *resource.NewQuantity(1, resource.DecimalSI)
+	resources.Limits[api.ResourceNvidiaGPU] = *resource.NewQuantity(2, resource.DecimalSI)
+	resources.Limits["pod.alpha.kubernetes.io/opaque-int-resource-foo"] = *resource.NewQuantity(3, resource.DecimalSI)
+
+	expected := resourceList{
+		api.ResourceCPU:       1000,
+		api.ResourceMemory:    2000,
+		api.ResourceNvidiaGPU: 2,
+		"pod.alpha.kubernetes.io/opaque-int-resource-foo": 3,
+	}
+	if actual := getResourcesForPacking(&resources, resourceList{}); !reflect.DeepEqual(expected, actual) {
+		t.Errorf("Expected: %v  Actual: %v", expected, actual)
+	}
+}
+
 func TestMostRequested(t *testing.T) {
 	labels1 := map[string]string{
 		"foo": "bar",
@@ -311,3 +577,433 @@ func TestMostRequested(t *testing.T) {
 		}
 	}
 }
+
+func TestWeightedMostRequested(t *testing.T) {
+	cpuAndMemory := api.PodSpec{
+		Containers: []api.Container{
+			{
+				Resources: makeResourceRequirements(1000, 2000, 0, 0),
+			},
+			{
+				Resources: makeResourceRequirements(2000, 3000, 0, 0),
+			},
+		},
+	}
+	tests := []struct {
+		pod          *api.Pod
+		pods         []*api.Pod
+		nodes        []*api.Node
+		weights      map[api.ResourceName]int64
+		expectedList schedulerapi.HostPriorityList
+		test         string
+	}{
+		{
+			/*
+				Node1 scores on 0-10 scale
+				CPU Score: 11 - ((4000 - 3000) *10) / 4000 = 8.5
+				Memory Score: 11 - ((10000 - 5000) *10) / 10000 = 6
+				Node1 Score: (8.5 + 6) / 2 = 7
+				Node2 scores on 0-10 scale
+				CPU Score: 11 - ((6000 - 3000) *10) / 6000 = 6
+				Memory Score: 11 - ((10000 - 5000) *10) / 10000 = 6
+				Node2 Score: (6 + 6) / 2 = 6
+			*/
+			pod:          &api.Pod{Spec: cpuAndMemory},
+			nodes:        []*api.Node{makeNode("machine1", 4000, 10000), makeNode("machine2", 6000, 10000)},
+			weights:      map[api.ResourceName]int64{api.ResourceCPU: 1, api.ResourceMemory: 1},
+			expectedList: []schedulerapi.HostPriority{{Host: "machine1", Score: 7}, {Host: "machine2", Score: 6}},
+			test:         "equal weights match MostUsed",
+		},
+		{
+			/*
+				Node1 scores on 0-10 scale
+				CPU Score: 11 - ((4000 - 3000) *10) / 4000 = 8.5
+				Memory Score: 11 - ((20000 - 5000) *10) / 20000 = 3.5
+				Node1 Score: (8.5 + 3 * 3.5) / 4 = 4
+				Node2 scores on 0-10 scale
+				CPU Score: 11 - ((12000 - 3000) *10) / 12000 = 3.5
+				Memory Score: 11 - ((10000 - 5000) *10) / 10000 = 6
+				Node2 Score: (3.5 + 3 * 6) / 4 = 5
+			*/
+			pod:          &api.Pod{Spec: cpuAndMemory},
+			nodes:        []*api.Node{makeNode("machine1", 4000, 20000), makeNode("machine2", 12000, 10000)},
+			weights:      map[api.ResourceName]int64{api.ResourceCPU: 1, api.ResourceMemory: 3},
+			expectedList: []schedulerapi.HostPriority{{Host: "machine1", Score: 4}, {Host: "machine2", Score: 5}},
+			test:         "memory weighted, differently sized machines",
+		},
+		{
+			/*
+				Node1 scores on 0-10 scale
+				CPU Score: 11 - ((4000 - 3000) *10) / 4000 = 8.5
+				Node1 Score: 8
+				Node2 scores on 0-10 scale
+				CPU Score: 11 - ((12000 - 3000) *10) / 12000 = 3.5
+				Node2 Score: 3
+			*/
+			pod:          &api.Pod{Spec: cpuAndMemory},
+			nodes:        []*api.Node{makeNode("machine1", 4000, 20000), makeNode("machine2", 12000, 10000)},
+			weights:      map[api.ResourceName]int64{api.ResourceCPU: 1, api.ResourceMemory: 0},
+			expectedList: []schedulerapi.HostPriority{{Host: "machine1", Score: 8}, {Host: "machine2", Score: 3}},
+			test:         "cpu only weight",
+		},
+		{
+			/*
+				Node1 scores on 0-10 scale
+				CPU Score: ((4000 - 6000) *10) / 4000 = 0
+				Memory Score: 11 - ((10000 - 5000) *10) / 10000 = 6
+				Node1 Score: 6
+			*/
+			pod:          &api.Pod{Spec: cpuAndMemory},
+			nodes:        []*api.Node{makeNode("machine1", 4000, 10000)},
+			weights:      map[api.ResourceName]int64{api.ResourceCPU: 0, api.ResourceMemory: 1},
+			expectedList: []schedulerapi.HostPriority{{Host: "machine1", Score: 6}},
+			test:         "unweighted resource exceeding capacity is ignored",
+			pods: []*api.Pod{
+				{Spec: api.PodSpec{NodeName: "machine1", Containers: []api.Container{{Resources: makeResourceRequirements(3000, 0, 0, 0)}}}},
+			},
+		},
+		{
+			/*
+				Node1 scores on 0-10 scale
+				CPU Score: 11 - ((4000 - 3000) *10) / 4000 = 8.5
+				Memory Score: 11 - ((10000 - 5000) *10) / 10000 = 6
+				Node1 Score: (8.5 + 6) / 2 = 7
+			*/
+			pod:          &api.Pod{Spec: cpuAndMemory},
+			nodes:        []*api.Node{makeNode("machine1", 4000, 10000)},
+			weights:      map[api.ResourceName]int64{},
+			expectedList: []schedulerapi.HostPriority{{Host: "machine1", Score: 7}},
+			test:         "no weights uses the default weight",
+		},
+		{
+			pod:          &api.Pod{Spec: cpuAndMemory},
+			nodes:        []*api.Node{makeNode("machine1", 4000, 10000)},
+			weights:      map[api.ResourceName]int64{api.ResourceCPU: 0, api.ResourceMemory: 0},
+			expectedList: []schedulerapi.HostPriority{{Host: "machine1", Score: 0}},
+			test:         "all weights zero",
+		},
+	}
+
+	for _, test := range tests {
+		priority := NewWeightedMostRequestedPriority(test.weights, defaultScoreConfig)
+		list, err := priority(test.pod, schedulercache.CreateNodeNameToInfoMap(test.pods, test.nodes), test.nodes)
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if !reflect.DeepEqual(test.expectedList, list) {
+			t.Errorf("%s: expected %#v, got %#v", test.test, test.expectedList, list)
+		}
+	}
+}
+
+func TestMostRequestedExtendedResources(t *testing.T) {
+	gpuPod := api.PodSpec{
+		Containers: []api.Container{
+			{
+				Resources: makeResourceRequirements(1000, 2000, 0, 0),
+			},
+		},
+	}
+	gpuPod.Containers[0].Resources.Requests[api.ResourceNvidiaGPU] = *resource.NewQuantity(1, resource.DecimalSI)
+	gpuPod2 := gpuPod
+	gpuPod2.NodeName = "machine2"
+
+	gpuNode := func(name string, gpus int64) *api.Node {
+		node := makeNode(name, 4000, 8000)
+		node.Status.Capacity[api.ResourceNvidiaGPU] = *resource.NewQuantity(gpus, resource.DecimalSI)
+		return node
+	}
+
+	tests := []struct {
+		pod          *api.Pod
+		pods         []*api.Pod
+		nodes        []*api.Node
+		expectedList schedulerapi.HostPriorityList
+		test         string
+	}{
+		{
+			/*
+				Node1 scores on 0-10 scale
+				CPU Score: 11 - ((4000 - 1000) *10) / 4000 = 3.5
+				Memory Score: 11 - ((8000 - 2000) *10) / 8000 = 3.5
+				GPU Score: 11 - ((4 - 1) *10) / 4 = 3.5
+				Node1 Score: (3.5 + 3.5 + 3.5) / 3 = 3
+				Node2 scores on 0-10 scale
+				CPU Score: 11 - ((4000 - 2000) *10) / 4000 = 6
+				Memory Score: 11 - ((8000 - 4000) *10) / 8000 = 6
+				GPU Score: 11 - ((4 - 2) *10) / 4 = 6
+				Node2 Score: (6 + 6 + 6) / 3 = 6
+			*/
+			pod:          &api.Pod{Spec: gpuPod},
+			nodes:        []*api.Node{gpuNode("machine1", 4), gpuNode("machine2", 4)},
+			expectedList: []schedulerapi.HostPriority{{Host: "machine1", Score: 3}, {Host: "machine2", Score: 6}},
+			test:         "gpu requested, gpu pods scheduled",
+			pods:         []*api.Pod{{Spec: gpuPod2}},
+		},
+		{
+			pod:          &api.Pod{Spec: gpuPod},
+			nodes:        []*api.Node{makeNode("machine1", 4000, 8000)},
+			expectedList: []schedulerapi.HostPriority{{Host: "machine1", Score: 0}},
+			test:         "gpu requested, node without gpus",
+		},
+	}
+
+	for _, test := range tests {
+		list, err := MostRequestedPriority(test.pod, schedulercache.CreateNodeNameToInfoMap(test.pods, test.nodes), test.nodes)
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if !reflect.DeepEqual(test.expectedList, list) {
+			t.Errorf("%s: expected %#v, got %#v", test.test, test.expectedList, list)
+		}
+	}
+}
+
+func TestFineGrainedMostRequested(t *testing.T) {
+	pod := &api.Pod{
+		Spec: api.PodSpec{
+			Containers: []api.Container{
+				{
+					Resources: makeResourceRequirements(1000, 1000, 0, 0),
+				},
+			},
+		},
+	}
+	existingPod := func(node string, milliCPU, memory int64) *api.Pod {
+		return &api.Pod{
+			Spec: api.PodSpec{
+				NodeName: node,
+				Containers: []api.Container{
+					{
+						Resources: makeResourceRequirements(milliCPU, memory, 0, 0),
+					},
+				},
+			},
+		}
+	}
+	nodes := []*api.Node{makeNode("machine1", 10000, 10000), makeNode("machine2", 10000, 10000)}
+	pods := []*api.Pod{existingPod("machine1", 5100, 5100), existingPod("machine2", 5900, 5900)}
+
+	tests := []struct {
+		scoring      ScoreConfig
+		expectedList schedulerapi.HostPriorityList
+		test         string
+	}{
+		{
+			/*
+				Node1 scores on 0-10 scale
+				CPU Score: 11 - ((10000 - 6100) *10) / 10000 = 7.1
+				Memory Score: 11 - ((10000 - 6100) *10) / 10000 = 7.1
+				Node1 Score: (7 + 7) / 2 = 7
+				Node2 scores on 0-10 scale
+				CPU Score: 11 - ((10000 - 6900) *10) / 10000 = 7.9
+				Memory Score: 11 - ((10000 - 6900) *10) / 10000 = 7.9
+				Node2 Score: (7 + 7) / 2 = 7
+			*/
+			scoring:      ScoreConfig{MaxScore: 10},
+			expectedList: []schedulerapi.HostPriority{{Host: "machine1", Score: 7}, {Host: "machine2", Score: 7}},
+			test:         "bucketed scores tie",
+		},
+		{
+			/*
+				Node1 scores on 0-100 scale
+				CPU Score: 1 + (6100 * 99) / 10000 = 61
+				Memory Score: 1 + (6100 * 99) / 10000 = 61
+				Node1 Score: (61 + 61) / 2 = 61
+				Node2 scores on 0-100 scale
+				CPU Score: 1 + (6900 * 99) / 10000 = 69
+				Memory Score: 1 + (6900 * 99) / 10000 = 69
+				Node2 Score: (69 + 69) / 2 = 69
+			*/
+			scoring:      ScoreConfig{MaxScore: 100},
+			expectedList: []schedulerapi.HostPriority{{Host: "machine1", Score: 61}, {Host: "machine2", Score: 69}},
+			test:         "fine grained scores",
+		},
+		{
+			/*
+				Node1 Score: 61 * 10 / 69 = 8
+				Node2 Score: 69 * 10 / 69 = 10
+			*/
+			scoring:      ScoreConfig{MaxScore: 100, Normalize: true},
+			expectedList: []schedulerapi.HostPriority{{Host: "machine1", Score: 8}, {Host: "machine2", Score: 10}},
+			test:         "fine grained scores normalized",
+		},
+	}
+
+	for _, test := range tests {
+		priority := NewWeightedMostRequestedPriority(defaultResourceWeights, test.scoring)
+		list, err := priority(pod, schedulercache.CreateNodeNameToInfoMap(pods, nodes), nodes)
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if !reflect.DeepEqual(test.expectedList, list) {
+			t.Errorf("%s: expected %#v, got %#v", test.test, test.expectedList, list)
+		}
+	}
+}
+
+func TestLeastLeftover(t *testing.T) {
+	smallPod := &api.Pod{
+		Spec: api.PodSpec{
+			Containers: []api.Container{
+				{
+					Resources: makeResourceRequirements(1000, 4000, 0, 0),
+				},
+			},
+		},
+	}
+	existingPod := func(node string, milliCPU, memory int64) *api.Pod {
+		return &api.Pod{
+			Spec: api.PodSpec{
+				NodeName: node,
+				Containers: []api.Container{
+					{
+						Resources: makeResourceRequirements(milliCPU, memory, 0, 0),
+					},
+				},
+			},
+		}
+	}
+
+	tests := []struct {
+		pod          *api.Pod
+		pods         []*api.Pod
+		nodes        []*api.Node
+		expectedList schedulerapi.HostPriorityList
+		test         string
+	}{
+		{
+			/*
+				Node1 leftover after placement
+				CPU: (4000 - 1000) / 4000 = 0.75
+				Memory: (16000 - 16000) / 16000 = 0
+				Node1 Score: 1 + (1 - 0.75) * 9 = 3
+				Node2 leftover after placement
+				CPU: (4000 - 2000) / 4000 = 0.5
+				Memory: (16000 - 8000) / 16000 = 0.5
+				Node2 Score: 1 + (1 - 0.5) * 9 = 5
+			*/
+			pod:          smallPod,
+			nodes:        []*api.Node{makeNode("machine1", 4000, 16000), makeNode("machine2", 4000, 16000)},
+			expectedList: []schedulerapi.HostPriority{{Host: "machine1", Score: 3}, {Host: "machine2", Score: 5}},
+			test:         "avoid leaving a cpu hole",
+			pods:         []*api.Pod{existingPod("machine1", 0, 12000), existingPod("machine2", 1000, 4000)},
+		},
+		{
+			/*
+				Node1 leftover after placement
+				CPU: (4000 - 4000) / 4000 = 0
+				Memory: (16000 - 16000) / 16000 = 0
+				Node1 Score: 1 + (1 - 0) * 9 = 10
+			*/
+			pod:          smallPod,
+			nodes:        []*api.Node{makeNode("machine1", 4000, 16000)},
+			expectedList: []schedulerapi.HostPriority{{Host: "machine1", Score: 10}},
+			test:         "exact fit",
+			pods:         []*api.Pod{existingPod("machine1", 3000, 12000)},
+		},
+		{
+			pod:          smallPod,
+			nodes:        []*api.Node{makeNode("machine1", 4000, 16000), makeNode("machine2", 0, 0)},
+			expectedList: []schedulerapi.HostPriority{{Host: "machine1", Score: 0}, {Host: "machine2", Score: 0}},
+			test:         "does not fit",
+			pods:         []*api.Pod{existingPod("machine1", 4000, 0)},
+		},
+	}
+
+	priority := NewLeastLeftoverPriority(defaultScoreConfig)
+	for _, test := range tests {
+		list, err := priority(test.pod, schedulercache.CreateNodeNameToInfoMap(test.pods, test.nodes), test.nodes)
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if !reflect.DeepEqual(test.expectedList, list) {
+			t.Errorf("%s: expected %#v, got %#v", test.test, test.expectedList, list)
+		}
+	}
+}
+
+func TestBalancedMostRequested(t *testing.T) {
+	pod := &api.Pod{
+		Spec: api.PodSpec{
+			Containers: []api.Container{
+				{
+					Resources: makeResourceRequirements(1000, 1000, 0, 0),
+				},
+			},
+		},
+	}
+	existingPod := func(node string, milliCPU, memory int64) *api.Pod {
+		return &api.Pod{
+			Spec: api.PodSpec{
+				NodeName: node,
+				Containers: []api.Container{
+					{
+						Resources: makeResourceRequirements(milliCPU, memory, 0, 0),
+					},
+				},
+			},
+		}
+	}
+
+	tests := []struct {
+		pods         []*api.Pod
+		nodes        []*api.Node
+		scoring      ScoreConfig
+		expectedList schedulerapi.HostPriorityList
+		test         string
+	}{
+		{
+			/*
+				Node1 utilization after placement
+				CPU: 10000 / 10000 = 1
+				Memory: 1000 / 10000 = 0.1
+				Node1 Score: 1 + (0.55 * (1 - 0.9)) * 9 = 1
+				Node2 utilization after placement
+				CPU: 6000 / 10000 = 0.6
+				Memory: 6000 / 10000 = 0.6
+				Node2 Score: 1 + (0.6 * (1 - 0)) * 9 = 6
+			*/
+			nodes:        []*api.Node{makeNode("machine1", 10000, 10000), makeNode("machine2", 10000, 10000)},
+			scoring:      defaultScoreConfig,
+			expectedList: []schedulerapi.HostPriority{{Host: "machine1", Score: 1}, {Host: "machine2", Score: 6}},
+			test:         "exhausted cpu scores low",
+			pods:         []*api.Pod{existingPod("machine1", 9000, 0), existingPod("machine2", 5000, 5000)},
+		},
+		{
+			/*
+				Node1 utilization after placement
+				CPU: 8000 / 10000 = 0.8
+				Memory: 7000 / 10000 = 0.7
+				Node1 Score: 1 + (0.75 * (1 - 0.1)) * 99 = 67
+				Node2 utilization after placement
+				CPU: 6000 / 10000 = 0.6
+				Memory: 6000 / 10000 = 0.6
+				Node2 Score: 1 + (0.6 * (1 - 0)) * 99 = 60
+			*/
+			nodes:        []*api.Node{makeNode("machine1", 10000, 10000), makeNode("machine2", 10000, 10000)},
+			scoring:      ScoreConfig{MaxScore: 100},
+			expectedList: []schedulerapi.HostPriority{{Host: "machine1", Score: 67}, {Host: "machine2", Score: 60}},
+			test:         "nearly balanced fuller node wins",
+			pods:         []*api.Pod{existingPod("machine1", 7000, 6000), existingPod("machine2", 5000, 5000)},
+		},
+		{
+			nodes:        []*api.Node{makeNode("machine1", 10000, 10000)},
+			scoring:      defaultScoreConfig,
+			expectedList: []schedulerapi.HostPriority{{Host: "machine1", Score: 0}},
+			test:         "does not fit",
+			pods:         []*api.Pod{existingPod("machine1", 9500, 0)},
+		},
+	}
+
+	for _, test := range tests {
+		priority := NewBalancedMostRequestedPriority(test.scoring)
+		list, err := priority(pod, schedulercache.CreateNodeNameToInfoMap(test.pods, test.nodes), test.nodes)
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if !reflect.DeepEqual(test.expectedList, list) {
+			t.Errorf("%s: expected %#v, got %#v", test.test, test.expectedList, list)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package algorithm
+
+import (
+	"sort"
+	"sync"
+
+	"k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/labels"
+	"k8s.io/kubernetes/plugin/pkg/scheduler/algorithm"
+	"k8s.io/kubernetes/plugin/pkg/scheduler/factory"
+)
+
+// The predicates and priorities of this package, kept so they can be used outside of the scheduler,
+// e.g. by the simulator. They are not instrumented, so using them affects neither the metrics nor explain mode
+var (
+	predicateFactories = map[string]factory.FitPredicateFactory{}
+	priorityFactories  = map[string]factory.PriorityConfigFactory{}
+)
+
+// The listers of the running scheduler, kept once it builds one of the predicates or priorities of this package
+var (
+	schedulerListersLock sync.RWMutex
+	schedulerListers     *factory.PluginFactoryArgs
+)
+
+// recordSchedulerListers keeps the listers the scheduler builds its predicates and priorities with
+func recordSchedulerListers(args factory.PluginFactoryArgs) {
+	if args.PodLister == nil || args.NodeLister == nil {
+		return
+	}
+	schedulerListersLock.Lock()
+	defer schedulerListersLock.Unlock()
+	schedulerListers = &args
+}
+
+//ListSchedulerCache returns the nodes the running scheduler places pods on and the pods in its cache, including the
+//pods it assumed but has not bound yet. 'ok' is false until the scheduler uses a predicate or priority of this package
+func ListSchedulerCache() (nodes []*api.Node, pods []*api.Pod, ok bool, err error) {
+	schedulerListersLock.RLock()
+	listers := schedulerListers
+	schedulerListersLock.RUnlock()
+	if listers == nil {
+		return nil, nil, false, nil
+	}
+
+	nodeList, err := listers.NodeLister.List()
+	if err != nil {
+		return nil, nil, true, err
+	}
+	for i := range nodeList.Items {
+		nodes = append(nodes, &nodeList.Items[i])
+	}
+
+	pods, err = listers.PodLister.List(labels.Everything())
+	if err != nil {
+		return nil, nil, true, err
+	}
+	return nodes, pods, true, nil
+}
+
+// registerFitPredicate registers the predicate with the scheduler and with this package
+func registerFitPredicate(name string, predicate algorithm.FitPredicate) {
+	registerFitPredicateFactory(name, func(args factory.PluginFactoryArgs) algorithm.FitPredicate {
+		return predicate
+	})
+}
+
+// registerFitPredicateFactory registers the predicate factory with this package, and instrumented with the scheduler.
+// The scheduler building it also hands over its listers
+func registerFitPredicateFactory(name string, predicateFactory factory.FitPredicateFactory) {
+	predicateFactories[name] = predicateFactory
+	factory.RegisterFitPredicateFactory(name, func(args factory.PluginFactoryArgs) algorithm.FitPredicate {
+		recordSchedulerListers(args)
+		return instrumentPredicate(name, predicateFactory(args))
+	})
+}
+
+// registerPriorityConfigFactory registers the priority factory with this package, and instrumented with the scheduler.
+// The scheduler building it also hands over its listers
+func registerPriorityConfigFactory(name string, priorityFactory factory.PriorityConfigFactory) {
+	priorityFactories[name] = priorityFactory
+	factory.RegisterPriorityConfigFactory(name, factory.PriorityConfigFactory{
+		Function: func(args factory.PluginFactoryArgs) algorithm.PriorityFunction {
+			recordSchedulerListers(args)
+			return instrumentPriority(name, priorityFactory.Function(args))
+		},
+		Weight: priorityFactory.Weight,
+	})
+}
+
+//PredicateNames returns the names of the predicates registered by this package, sorted
+func PredicateNames() []string {
+	names := []string{}
+	for name := range predicateFactories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+//PriorityNames returns the names of the priorities registered by this package, sorted
+func PriorityNames() []string {
+	names := []string{}
+	for name := range priorityFactories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,43 @@
+package algorithm
+
+import (
+	"testing"
+
+	"k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/plugin/pkg/scheduler/algorithm"
+	"k8s.io/kubernetes/plugin/pkg/scheduler/factory"
+)
+
+type testNodeLister []*api.Node
+
+func (l testNodeLister) List() (api.NodeList, error) {
+	list := api.NodeList{}
+	for _, node := range l {
+		list.Items = append(list.Items, *node)
+	}
+	return list, nil
+}
+
+func TestListSchedulerCache(t *testing.T) {
+	defer func(listers *factory.PluginFactoryArgs) { schedulerListers = listers }(schedulerListers)
+	schedulerListers = nil
+
+	if _, _, ok, _ := ListSchedulerCache(); ok {
+		t.Fatalf("Expected no scheduler cache before the scheduler builds a predicate")
+	}
+
+	assumed := createSimulatedPod("assumed", 1000, 1000)
+	assumed.Spec.NodeName = "node-a"
+	recordSchedulerListers(factory.PluginFactoryArgs{
+		PodLister:  algorithm.FakePodLister{assumed},
+		NodeLister: testNodeLister{createSimulatedNode("node-a", 4000, 10000)},
+	})
+
+	nodes, pods, ok, err := ListSchedulerCache()
+	if !ok || err != nil {
+		t.Fatalf("Expected the scheduler cache, got %t %v", ok, err)
+	}
+	if len(nodes) != 1 || nodes[0].Name != "node-a" || len(pods) != 1 || pods[0].Name != "assumed" {
+		t.Errorf("Unexpected scheduler cache %v %v", nodes, pods)
+	}
+}
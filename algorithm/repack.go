@@ -0,0 +1,283 @@
+package algorithm
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/labels"
+)
+
+const (
+	// Annotation the kubelet puts on the API server copy of a static pod
+	mirrorPodAnnotation = "kubernetes.io/config.mirror"
+	// Scale the occupancy of nodes is ranked on. Finer than the scheduler's 0-10 so nodes
+	// with similar usage still sort apart
+	repackMaxScore = 100
+)
+
+// The predicates the pods of a drained node must pass on the node they move to
+var repackPredicates = []string{
+	nodeOutOfDiskPred,
+	nodeConditionsPred,
+	podOverCommitNodePred,
+	deisUniqueAppPred,
+	uniqueAppPred,
+	appTopologySpreadPred,
+	avoidScaleDownPred,
+	spotNodePred,
+}
+
+//PodMove is a pod the repacker moves off a node it empties
+type PodMove struct {
+	Pod  *api.Pod
+	From string
+	To   string
+}
+
+//RepackPlan is the set of moves that empties the least utilized nodes
+type RepackPlan struct {
+	//Nodes are the nodes the moves empty, least utilized first
+	Nodes []string
+	Moves []PodMove
+	//Skipped gives the reason each node considered could not be emptied
+	Skipped map[string]string
+}
+
+//DisruptionBudget is the number of pods matching Selector in Namespace that may be evicted, e.g. from a PodDisruptionBudget
+type DisruptionBudget struct {
+	Namespace string
+	Name      string
+	Selector  labels.Selector
+	Allowed   int
+}
+
+func (b *DisruptionBudget) matches(pod *api.Pod) bool {
+	return b.Namespace == pod.Namespace && b.Selector.Matches(labels.Set(pod.Labels))
+}
+
+// repackNode is a node and how full it is with the math of MostRequestedPriority
+type repackNode struct {
+	node      *api.Node
+	occupancy int
+	pods      []*api.Pod
+}
+
+//PlanRepack finds the moves that empty up to 'maxNodes' of the least utilized nodes, ranked by their usage when
+//planning starts. Pods are moved with the packing predicates and MostUsed onto the nodes that are not emptied.
+//A node is only emptied when every pod that is not a static or daemon pod can move, the pods are managed by a
+//controller that will recreate them and the 'budgets' allow the evictions. Nodes that pods are moved to are not
+//emptied, so every pod moves at most once
+func PlanRepack(nodes []*api.Node, pods []*api.Pod, budgets []DisruptionBudget, maxNodes int) (*RepackPlan, error) {
+	plan := &RepackPlan{
+		Nodes:   []string{},
+		Moves:   []PodMove{},
+		Skipped: map[string]string{},
+	}
+
+	allowed := make([]int, len(budgets))
+	for i := range budgets {
+		allowed[i] = budgets[i].Allowed
+	}
+
+	assigned := []*api.Pod{}
+	for _, pod := range pods {
+		if pod.Spec.NodeName != "" {
+			assigned = append(assigned, pod)
+		}
+	}
+
+	emptied := map[string]bool{}
+	received := map[string]bool{}
+	for _, candidate := range rankNodesForRepack(nodes, assigned) {
+		if len(plan.Nodes) >= maxNodes {
+			break
+		}
+		if received[candidate.node.Name] {
+			plan.Skipped[candidate.node.Name] = "Node receives pods moved off other nodes"
+			continue
+		}
+
+		movable := []*api.Pod{}
+		for _, pod := range assigned {
+			if pod.Spec.NodeName == candidate.node.Name && !isDaemonPod(pod) {
+				movable = append(movable, pod)
+			}
+		}
+		if len(movable) == 0 {
+			continue
+		}
+
+		targets := []*api.Node{}
+		for _, node := range nodes {
+			if node.Name != candidate.node.Name && !emptied[node.Name] {
+				targets = append(targets, node)
+			}
+		}
+		remaining := []*api.Pod{}
+		for _, pod := range assigned {
+			if pod.Spec.NodeName != candidate.node.Name && !emptied[pod.Spec.NodeName] {
+				remaining = append(remaining, pod)
+			}
+		}
+
+		moves, reason, err := planNodeMoves(candidate.node, movable, targets, remaining, budgets, allowed)
+		if err != nil {
+			return nil, err
+		}
+		if reason != "" {
+			plan.Skipped[candidate.node.Name] = reason
+			continue
+		}
+
+		emptied[candidate.node.Name] = true
+		plan.Nodes = append(plan.Nodes, candidate.node.Name)
+		plan.Moves = append(plan.Moves, moves...)
+		for _, move := range moves {
+			received[move.To] = true
+			for i, pod := range assigned {
+				if pod == move.Pod {
+					placed := *pod
+					placed.Spec.NodeName = move.To
+					assigned[i] = &placed
+				}
+			}
+		}
+	}
+
+	return plan, nil
+}
+
+// planNodeMoves places the pods of 'node' on the 'targets', largest first. The reason is set when a pod
+// cannot move, in which case 'allowed' is left unchanged
+func planNodeMoves(node *api.Node, pods []*api.Pod, targets []*api.Node, assigned []*api.Pod, budgets []DisruptionBudget, allowed []int) ([]PodMove, string, error) {
+	simulator, err := NewSimulator(targets, assigned, repackPredicates, map[string]int{"MostUsed": 1})
+	if err != nil {
+		return nil, "", err
+	}
+
+	sorted := append([]*api.Pod{}, pods...)
+	sort.Stable(podsByRequest{pods: sorted, node: node})
+
+	left := append([]int{}, allowed...)
+	moves := []PodMove{}
+	for _, pod := range sorted {
+		if !isReplicatedPod(pod) {
+			return nil, fmt.Sprintf("Pod %s/%s is not managed by a controller", pod.Namespace, pod.Name), nil
+		}
+		for i := range budgets {
+			if !budgets[i].matches(pod) {
+				continue
+			}
+			if left[i] <= 0 {
+				return nil, fmt.Sprintf("Pod %s/%s is protected by disruption budget %s", pod.Namespace, pod.Name, budgets[i].Name), nil
+			}
+			left[i]--
+		}
+
+		placement, err := simulator.Place(pod)
+		if err != nil {
+			return nil, "", err
+		}
+		if placement.Node == "" {
+			return nil, fmt.Sprintf("Pod %s/%s does not fit on any other node", pod.Namespace, pod.Name), nil
+		}
+		moves = append(moves, PodMove{Pod: pod, From: node.Name, To: placement.Node})
+	}
+
+	copy(allowed, left)
+	return moves, "", nil
+}
+
+// rankNodesForRepack returns the nodes least utilized first. Ties go to the node with the fewest pods, then by name
+func rankNodesForRepack(nodes []*api.Node, pods []*api.Pod) []repackNode {
+	podsByNode := map[string][]*api.Pod{}
+	for _, pod := range pods {
+		podsByNode[pod.Spec.NodeName] = append(podsByNode[pod.Spec.NodeName], pod)
+	}
+
+	ranked := []repackNode{}
+	for _, node := range nodes {
+		ranked = append(ranked, repackNode{
+			node:      node,
+			occupancy: nodeOccupancy(node, podsByNode[node.Name]),
+			pods:      podsByNode[node.Name],
+		})
+	}
+
+	sort.Sort(byOccupancy(ranked))
+	return ranked
+}
+
+// nodeOccupancy scores how full a node is with the pods on it, with the math of MostRequestedPriority
+func nodeOccupancy(node *api.Node, pods []*api.Pod) int {
+	total := resourceList{}
+	for _, pod := range pods {
+		total.add(getResourcesForPod(pod, node))
+	}
+
+	scores := map[api.ResourceName]int{}
+	for _, name := range total.names() {
+		scores[name] = calculateScore(total[name], getCapacity(name, node), node.Name, repackMaxScore)
+	}
+	return calculateWeightedScore(scores, defaultResourceWeights)
+}
+
+// isDaemonPod returns true for static pods and pods of a DaemonSet. They stay on their node when it is emptied
+func isDaemonPod(pod *api.Pod) bool {
+	if _, exists := pod.Annotations[mirrorPodAnnotation]; exists {
+		return true
+	}
+	ref := getCreatedByReference(pod)
+	return ref != nil && ref.Kind == "DaemonSet"
+}
+
+// isReplicatedPod returns true when a controller will recreate the pod once it is evicted
+func isReplicatedPod(pod *api.Pod) bool {
+	return getCreatedByReference(pod) != nil
+}
+
+func getCreatedByReference(pod *api.Pod) *api.ObjectReference {
+	value, exists := pod.Annotations[api.CreatedByAnnotation]
+	if !exists {
+		return nil
+	}
+
+	ref := &api.SerializedReference{}
+	if err := json.Unmarshal([]byte(value), ref); err != nil {
+		return nil
+	}
+	return &ref.Reference
+}
+
+type byOccupancy []repackNode
+
+func (n byOccupancy) Len() int { return len(n) }
+func (n byOccupancy) Less(i, j int) bool {
+	if n[i].occupancy != n[j].occupancy {
+		return n[i].occupancy < n[j].occupancy
+	}
+	if len(n[i].pods) != len(n[j].pods) {
+		return len(n[i].pods) < len(n[j].pods)
+	}
+	return n[i].node.Name < n[j].node.Name
+}
+func (n byOccupancy) Swap(i, j int) { n[i], n[j] = n[j], n[i] }
+
+// podsByRequest sorts pods by the resources they request on a node, largest CPU then memory first
+type podsByRequest struct {
+	pods []*api.Pod
+	node *api.Node
+}
+
+func (p podsByRequest) Len() int { return len(p.pods) }
+func (p podsByRequest) Less(i, j int) bool {
+	first := getResourcesForPod(p.pods[i], p.node)
+	second := getResourcesForPod(p.pods[j], p.node)
+	if first[api.ResourceCPU] != second[api.ResourceCPU] {
+		return first[api.ResourceCPU] > second[api.ResourceCPU]
+	}
+	return first[api.ResourceMemory] > second[api.ResourceMemory]
+}
+func (p podsByRequest) Swap(i, j int) { p.pods[i], p.pods[j] = p.pods[j], p.pods[i] }
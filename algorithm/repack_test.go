@@ -0,0 +1,209 @@
+package algorithm
+
+import (
+	"strings"
+	"testing"
+
+	"k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/labels"
+)
+
+func createRepackPod(name, node, controller string, milliCPU int64) *api.Pod {
+	pod := createSimulatedPod(name, milliCPU, 1000)
+	pod.Spec.NodeName = node
+	if controller != "" {
+		pod.Annotations = map[string]string{
+			api.CreatedByAnnotation: `{"kind":"SerializedReference","reference":{"kind":"` + controller + `","namespace":"default","name":"` + name + `"}}`,
+		}
+	}
+	return pod
+}
+
+func TestPlanRepack(t *testing.T) {
+	tests := []struct {
+		testName string
+		pods     []*api.Pod
+		budgets  []DisruptionBudget
+		maxNodes int
+		nodes    []string
+		moves    map[string]string
+		skipped  []string
+	}{
+		{
+			testName: "EmptyLeastUsed",
+			pods: []*api.Pod{
+				createRepackPod("full", "node-a", "ReplicaSet", 2500),
+				createRepackPod("half", "node-b", "ReplicaSet", 1000),
+				createRepackPod("small", "node-c", "ReplicaSet", 300),
+				createRepackPod("daemon", "node-c", "DaemonSet", 100),
+			},
+			maxNodes: 2,
+			nodes:    []string{"node-c", "node-b"},
+			moves:    map[string]string{"small": "node-a", "half": "node-a"},
+		},
+		{
+			testName: "MaxNodes",
+			pods: []*api.Pod{
+				createRepackPod("full", "node-a", "ReplicaSet", 2500),
+				createRepackPod("half", "node-b", "ReplicaSet", 1000),
+				createRepackPod("small", "node-c", "ReplicaSet", 500),
+			},
+			maxNodes: 1,
+			nodes:    []string{"node-c"},
+			moves:    map[string]string{"small": "node-a"},
+		},
+		{
+			testName: "DoesNotFit",
+			pods: []*api.Pod{
+				createRepackPod("full", "node-a", "ReplicaSet", 3500),
+				createRepackPod("large", "node-b", "ReplicaSet", 3000),
+				createRepackPod("medium", "node-c", "ReplicaSet", 2000),
+			},
+			maxNodes: 3,
+			nodes:    []string{},
+			moves:    map[string]string{},
+			skipped:  []string{"node-c", "node-b", "node-a"},
+		},
+		{
+			testName: "Unreplicated",
+			pods: []*api.Pod{
+				createRepackPod("full", "node-a", "ReplicaSet", 2500),
+				createRepackPod("half", "node-b", "ReplicaSet", 1000),
+				createRepackPod("bare", "node-c", "", 500),
+			},
+			maxNodes: 1,
+			nodes:    []string{"node-b"},
+			moves:    map[string]string{"half": "node-a"},
+			skipped:  []string{"node-c"},
+		},
+		{
+			testName: "DisruptionBudget",
+			pods: []*api.Pod{
+				createRepackPod("full", "node-a", "ReplicaSet", 2500),
+				createRepackPod("half", "node-b", "ReplicaSet", 1000),
+				createRepackPod("small", "node-c", "ReplicaSet", 500),
+			},
+			budgets: []DisruptionBudget{
+				{Namespace: "default", Name: "small", Selector: labels.SelectorFromSet(labels.Set{"app": "small"}), Allowed: 0},
+			},
+			maxNodes: 1,
+			nodes:    []string{"node-b"},
+			moves:    map[string]string{"half": "node-a"},
+			skipped:  []string{"node-c"},
+		},
+		{
+			// node-a and node-b tie, so the small pod moves to node-a, which is ranked next for emptying
+			testName: "MovedOnce",
+			pods: []*api.Pod{
+				createRepackPod("first", "node-a", "ReplicaSet", 1500),
+				createRepackPod("second", "node-b", "ReplicaSet", 1500),
+				createRepackPod("small", "node-c", "ReplicaSet", 300),
+			},
+			maxNodes: 3,
+			nodes:    []string{"node-c", "node-b"},
+			moves:    map[string]string{"small": "node-a", "second": "node-a"},
+			skipped:  []string{"node-a"},
+		},
+		{
+			testName: "UniqueDeisApp",
+			pods: func() []*api.Pod {
+				running := createRepackPod("web-v1-a", "node-a", "ReplicationController", 2000)
+				moving := createRepackPod("web-v1-b", "node-c", "ReplicationController", 500)
+				running.Labels = createDeisPod("v1").Labels
+				moving.Labels = createDeisPod("v1").Labels
+				return []*api.Pod{running, moving, createRepackPod("half", "node-b", "ReplicaSet", 1000)}
+			}(),
+			maxNodes: 1,
+			nodes:    []string{"node-c"},
+			moves:    map[string]string{"web-v1-b": "node-b"},
+		},
+	}
+
+	for _, test := range tests {
+		nodes := []*api.Node{
+			createSimulatedNode("node-a", 4000, 10000),
+			createSimulatedNode("node-b", 4000, 10000),
+			createSimulatedNode("node-c", 4000, 10000),
+		}
+		for _, pod := range test.pods {
+			if pod.Labels == nil {
+				pod.Labels = map[string]string{"app": pod.Name}
+			}
+		}
+
+		plan, err := PlanRepack(nodes, test.pods, test.budgets, test.maxNodes)
+		if err != nil {
+			t.Errorf("Test %s. Unexpected error %v", test.testName, err)
+			continue
+		}
+
+		if strings.Join(plan.Nodes, ",") != strings.Join(test.nodes, ",") {
+			t.Errorf("Test %s. Expected nodes %v emptied, got %v", test.testName, test.nodes, plan.Nodes)
+		}
+		if len(plan.Moves) != len(test.moves) {
+			t.Errorf("Test %s. Expected %d moves, got %+v", test.testName, len(test.moves), plan.Moves)
+		}
+		for _, move := range plan.Moves {
+			if test.moves[move.Pod.Name] != move.To {
+				t.Errorf("Test %s. Pod %s. Expected: %q Actual: %q", test.testName, move.Pod.Name, test.moves[move.Pod.Name], move.To)
+			}
+		}
+		if len(plan.Skipped) != len(test.skipped) {
+			t.Errorf("Test %s. Expected nodes %v skipped, got %v", test.testName, test.skipped, plan.Skipped)
+		}
+		for _, node := range test.skipped {
+			if plan.Skipped[node] == "" {
+				t.Errorf("Test %s. Expected a reason for skipping %s: %v", test.testName, node, plan.Skipped)
+			}
+		}
+	}
+}
+
+func TestPlanRepackNotRecorded(t *testing.T) {
+	defer func(recorder *decisionRecorder) { decisions = recorder }(decisions)
+	sink := &testDecisionSink{}
+	decisions = newDecisionRecorder()
+	decisions.addSink(sink)
+
+	nodes := []*api.Node{
+		createSimulatedNode("node-a", 4000, 10000),
+		createSimulatedNode("node-b", 4000, 10000),
+	}
+	pods := []*api.Pod{
+		createRepackPod("full", "node-a", "ReplicaSet", 2500),
+		createRepackPod("small", "node-b", "ReplicaSet", 500),
+	}
+
+	if _, err := PlanRepack(nodes, pods, nil, 1); err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	sendQueuedDecisions(decisions)
+	if decisions.current != nil || len(sink.records) != 0 {
+		t.Errorf("Expected planned placements not to be recorded by explain mode, got %d records", len(sink.records))
+	}
+}
+
+func TestIsDaemonPod(t *testing.T) {
+	mirror := createRepackPod("mirror", "node-a", "", 100)
+	mirror.Annotations = map[string]string{mirrorPodAnnotation: "hash"}
+
+	tests := []struct {
+		pod        *api.Pod
+		daemon     bool
+		replicated bool
+	}{
+		{createRepackPod("daemon", "node-a", "DaemonSet", 100), true, true},
+		{createRepackPod("replica", "node-a", "ReplicaSet", 100), false, true},
+		{createRepackPod("bare", "node-a", "", 100), false, false},
+		{mirror, true, false},
+	}
+
+	for _, test := range tests {
+		if isDaemonPod(test.pod) != test.daemon {
+			t.Errorf("Pod %s. Expected daemon %t", test.pod.Name, test.daemon)
+		}
+		if isReplicatedPod(test.pod) != test.replicated {
+			t.Errorf("Pod %s. Expected replicated %t", test.pod.Name, test.replicated)
+		}
+	}
+}
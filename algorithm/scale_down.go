@@ -0,0 +1,135 @@
+package algorithm
+
+import (
+	"github.com/golang/glog"
+	"k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/plugin/pkg/scheduler/algorithm"
+	schedulerapi "k8s.io/kubernetes/plugin/pkg/scheduler/api"
+	"k8s.io/kubernetes/plugin/pkg/scheduler/schedulercache"
+)
+
+const (
+	//ScaleDownCandidateAnnotation marks a node that can be removed from the cluster. The value is its ScaleDownState.
+	//It is also the key of the taint put on those nodes
+	ScaleDownCandidateAnnotation = "packscheduler.alpha.kubernetes.io/scale-down-candidate"
+	// Taint the cluster autoscaler puts on a node it is about to delete
+	clusterAutoscalerTaint = "ToBeDeletedByClusterAutoscaler"
+)
+
+//ScaleDownState is why a node can be removed from the cluster
+type ScaleDownState string
+
+const (
+	//ScaleDownEmpty is a node only running static and daemon pods
+	ScaleDownEmpty ScaleDownState = "empty"
+	//ScaleDownDrainable is a node whose pods can all move to the nodes that are kept
+	ScaleDownDrainable ScaleDownState = "drainable"
+)
+
+//ScaleDownCandidates returns the nodes that can be removed from the cluster. Empty nodes are never used to take
+//the pods of drained nodes, and the drainable nodes can all be drained together, as planned by PlanRepack
+func ScaleDownCandidates(nodes []*api.Node, pods []*api.Pod, budgets []DisruptionBudget) (map[string]ScaleDownState, error) {
+	candidates := map[string]ScaleDownState{}
+
+	used := map[string]bool{}
+	for _, pod := range pods {
+		if pod.Spec.NodeName != "" && !isDaemonPod(pod) {
+			used[pod.Spec.NodeName] = true
+		}
+	}
+
+	kept := []*api.Node{}
+	for _, node := range nodes {
+		if used[node.Name] {
+			kept = append(kept, node)
+		} else {
+			candidates[node.Name] = ScaleDownEmpty
+		}
+	}
+	keptPods := []*api.Pod{}
+	for _, pod := range pods {
+		if used[pod.Spec.NodeName] {
+			keptPods = append(keptPods, pod)
+		}
+	}
+
+	plan, err := PlanRepack(kept, keptPods, budgets, len(kept))
+	if err != nil {
+		return nil, err
+	}
+	for _, node := range plan.Nodes {
+		candidates[node] = ScaleDownDrainable
+	}
+
+	counts := map[ScaleDownState]int{ScaleDownEmpty: 0, ScaleDownDrainable: 0}
+	for _, state := range candidates {
+		counts[state]++
+	}
+	for state, count := range counts {
+		scaleDownCandidates.WithLabelValues(string(state)).Set(float64(count))
+	}
+
+	return candidates, nil
+}
+
+// scaleDownMark returns the annotation or taint marking the node for scale-down, or an empty string.
+// Without annotations in the policy, only the drainable candidates of this scheduler are marked: empty
+// candidates are often fresh capacity added for pending pods, which must not be kept off it
+func (p ScaleDownPolicy) scaleDownMark(node *api.Node) string {
+	annotations := p.Annotations
+	if annotations == nil {
+		annotations = []string{}
+		if ScaleDownState(node.Annotations[ScaleDownCandidateAnnotation]) == ScaleDownDrainable {
+			return ScaleDownCandidateAnnotation
+		}
+	}
+	for _, key := range annotations {
+		if _, exists := node.Annotations[key]; exists {
+			return key
+		}
+	}
+
+	keys := p.Taints
+	if keys == nil {
+		keys = []string{clusterAutoscalerTaint}
+	}
+	taints, err := api.GetTaintsFromNodeAnnotations(node.Annotations)
+	if err != nil {
+		glog.V(2).Infof("Unable to read the taints of node %s: %v", node.Name, err)
+		return ""
+	}
+	for _, taint := range taints {
+		for _, key := range keys {
+			if taint.Key == key {
+				return key
+			}
+		}
+	}
+	return ""
+}
+
+//AvoidScaleDown rejects the nodes the packing policy marks for scale-down, so pods are not evicted again when the node is removed.
+//Pods stay Pending when every node they fit on is marked, so the predicate is opt-in: the AvoidScaleDown priority alone is the
+//safe default, and the predicate should only be added to the scheduler policy when pending pods are preferred to moved pods
+func AvoidScaleDown(pod *api.Pod, meta interface{}, cacheInfo *schedulercache.NodeInfo) (bool, []algorithm.PredicateFailureReason, error) {
+	info := cacheInfo.Node()
+	if mark := packingPolicy.ScaleDown.scaleDownMark(info); mark != "" {
+		glog.V(10).Infof("Cannot schedule Pod %s, Because Node %v is marked for scale-down by %s", pod.Name, info.Name, mark)
+		return false, []algorithm.PredicateFailureReason{avoidScaleDownPredError}, nil
+	}
+	return true, nil, nil
+}
+
+//AvoidScaleDownPriority scores the nodes the packing policy marks for scale-down 0 and every other node 10.
+//Given a high weight it keeps pods off those nodes unless nothing else fits
+func AvoidScaleDownPriority(pod *api.Pod, nodeNameToInfo map[string]*schedulercache.NodeInfo, nodes []*api.Node) (schedulerapi.HostPriorityList, error) {
+	list := schedulerapi.HostPriorityList{}
+	for _, node := range nodes {
+		score := frameworkMaxPriority
+		if packingPolicy.ScaleDown.scaleDownMark(node) != "" {
+			score = 0
+		}
+		list = append(list, schedulerapi.HostPriority{Host: node.Name, Score: score})
+	}
+	return list, nil
+}
@@ -0,0 +1,180 @@
+package algorithm
+
+import (
+	"testing"
+
+	"k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/plugin/pkg/scheduler/algorithm"
+	"k8s.io/kubernetes/plugin/pkg/scheduler/schedulercache"
+)
+
+func TestScaleDownCandidates(t *testing.T) {
+	nodes := []*api.Node{
+		createSimulatedNode("node-a", 4000, 10000),
+		createSimulatedNode("node-b", 4000, 10000),
+		createSimulatedNode("node-c", 4000, 10000),
+		createSimulatedNode("node-d", 4000, 10000),
+	}
+	pods := []*api.Pod{
+		createRepackPod("full", "node-a", "ReplicaSet", 2500),
+		createRepackPod("small", "node-b", "ReplicaSet", 500),
+		createRepackPod("daemon-b", "node-b", "DaemonSet", 100),
+		createRepackPod("daemon-c", "node-c", "DaemonSet", 100),
+		createRepackPod("pending", "", "", 3000),
+	}
+
+	candidates, err := ScaleDownCandidates(nodes, pods, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	expected := map[string]ScaleDownState{
+		"node-b": ScaleDownDrainable,
+		"node-c": ScaleDownEmpty,
+		"node-d": ScaleDownEmpty,
+	}
+	if len(candidates) != len(expected) {
+		t.Errorf("Expected %v, got %v", expected, candidates)
+	}
+	for node, state := range expected {
+		if candidates[node] != state {
+			t.Errorf("Node %s. Expected: %q Actual: %q", node, state, candidates[node])
+		}
+	}
+}
+
+func TestScaleDownCandidatesAvoided(t *testing.T) {
+	defer func(policy ScaleDownPolicy) { packingPolicy.ScaleDown = policy }(packingPolicy.ScaleDown)
+	packingPolicy.ScaleDown = ScaleDownPolicy{}
+
+	nodes := []*api.Node{
+		createSimulatedNode("node-a", 4000, 10000),
+		createSimulatedNode("node-b", 4000, 10000),
+		createSimulatedNode("node-new", 4000, 10000),
+	}
+	pods := []*api.Pod{
+		createRepackPod("full", "node-a", "ReplicaSet", 2500),
+		createRepackPod("small", "node-b", "ReplicaSet", 500),
+	}
+
+	candidates, err := ScaleDownCandidates(nodes, pods, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	// Annotate the nodes the way the scale-down marker does
+	for _, node := range nodes {
+		if state, exists := candidates[node.Name]; exists {
+			node.Annotations = map[string]string{ScaleDownCandidateAnnotation: string(state)}
+		}
+	}
+
+	// The pending pod fits on the new empty node, but not on the node being drained
+	expected := map[string]bool{"node-a": false, "node-b": false, "node-new": true}
+	pending := createRepackPod("pending", "", "ReplicaSet", 2000)
+	nodeNameToInfo := schedulercache.CreateNodeNameToInfoMap(pods, nodes)
+	for _, node := range nodes {
+		fits := true
+		for _, predicate := range []algorithm.FitPredicate{PodOverCommitNode, AvoidScaleDown} {
+			fit, _, err := predicate(pending, nil, nodeNameToInfo[node.Name])
+			if err != nil {
+				t.Fatalf("Unexpected error %v", err)
+			}
+			fits = fits && fit
+		}
+		if fits != expected[node.Name] {
+			t.Errorf("Node %s (%q). Expected fit %t, got %t", node.Name, candidates[node.Name], expected[node.Name], fits)
+		}
+	}
+}
+
+func createScaleDownNode(name string, annotations map[string]string) *api.Node {
+	node := createSimulatedNode(name, 4000, 10000)
+	node.Annotations = annotations
+	return node
+}
+
+func TestAvoidScaleDown(t *testing.T) {
+	defer func(policy ScaleDownPolicy) { packingPolicy.ScaleDown = policy }(packingPolicy.ScaleDown)
+
+	tests := []struct {
+		testName string
+		policy   ScaleDownPolicy
+		node     *api.Node
+		fits     bool
+	}{
+		{
+			testName: "NotMarked",
+			node:     createScaleDownNode("node-a", nil),
+			fits:     true,
+		},
+		{
+			testName: "Candidate",
+			node:     createScaleDownNode("node-a", map[string]string{ScaleDownCandidateAnnotation: string(ScaleDownDrainable)}),
+			fits:     false,
+		},
+		{
+			testName: "EmptyCandidate",
+			node:     createScaleDownNode("node-a", map[string]string{ScaleDownCandidateAnnotation: string(ScaleDownEmpty)}),
+			fits:     true,
+		},
+		{
+			testName: "ClusterAutoscaler",
+			node:     createScaleDownNode("node-a", map[string]string{api.TaintsAnnotationKey: `[{"key": "ToBeDeletedByClusterAutoscaler", "value": "1478000000", "effect": "NoSchedule"}]`}),
+			fits:     false,
+		},
+		{
+			testName: "OtherTaint",
+			node:     createScaleDownNode("node-a", map[string]string{api.TaintsAnnotationKey: `[{"key": "dedicated", "value": "gpu", "effect": "NoSchedule"}]`}),
+			fits:     true,
+		},
+		{
+			testName: "CustomAnnotation",
+			policy:   ScaleDownPolicy{Annotations: []string{"example.com/draining"}},
+			node:     createScaleDownNode("node-a", map[string]string{"example.com/draining": ""}),
+			fits:     false,
+		},
+		{
+			testName: "CustomAnnotationReplacesDefault",
+			policy:   ScaleDownPolicy{Annotations: []string{"example.com/draining"}},
+			node:     createScaleDownNode("node-a", map[string]string{ScaleDownCandidateAnnotation: string(ScaleDownEmpty)}),
+			fits:     true,
+		},
+		{
+			testName: "CustomTaint",
+			policy:   ScaleDownPolicy{Taints: []string{"draining"}},
+			node:     createScaleDownNode("node-a", map[string]string{api.TaintsAnnotationKey: `[{"key": "draining", "effect": "PreferNoSchedule"}]`}),
+			fits:     false,
+		},
+	}
+
+	for _, test := range tests {
+		packingPolicy.ScaleDown = test.policy
+		info := schedulercache.NewNodeInfo()
+		info.SetNode(test.node)
+
+		fits, reasons, err := AvoidScaleDown(createResourcePod(100, 100, 0), nil, info)
+		if err != nil {
+			t.Errorf("Test %s. Unexpected error %v", test.testName, err)
+			continue
+		}
+		if fits != test.fits {
+			t.Errorf("Test %s. Expected fit %t, got %t", test.testName, test.fits, fits)
+		}
+		if !test.fits && !hasReason(reasons, avoidScaleDownPred) {
+			t.Errorf("Test %s. Expected the %s failure, got %v", test.testName, avoidScaleDownPred, reasons)
+		}
+
+		list, err := AvoidScaleDownPriority(createResourcePod(100, 100, 0), nil, []*api.Node{test.node})
+		if err != nil {
+			t.Errorf("Test %s. Unexpected error %v", test.testName, err)
+			continue
+		}
+		expected := 0
+		if test.fits {
+			expected = frameworkMaxPriority
+		}
+		if list[0].Score != expected {
+			t.Errorf("Test %s. Expected score %d, got %d", test.testName, expected, list[0].Score)
+		}
+	}
+}
@@ -0,0 +1,229 @@
+package algorithm
+
+import (
+	"fmt"
+	"sort"
+
+	"k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/labels"
+	"k8s.io/kubernetes/plugin/pkg/scheduler/algorithm"
+	"k8s.io/kubernetes/plugin/pkg/scheduler/factory"
+	"k8s.io/kubernetes/plugin/pkg/scheduler/schedulercache"
+)
+
+//SimulatedPlacement is the node the simulator placed a pod on
+type SimulatedPlacement struct {
+	Pod *api.Pod
+	//Node is empty when the pod did not fit on any node
+	Node string
+	//Failures counts the nodes rejected for each predicate failure reason
+	Failures map[string]int
+}
+
+//NodeUtilization is the amount of each resource used on a node against its capacity
+type NodeUtilization struct {
+	Node      string
+	Pods      int
+	Requested map[api.ResourceName]int64
+	Capacity  map[api.ResourceName]int64
+}
+
+//Simulator places pods on a set of nodes with the predicates and priorities of this package, the way the scheduler would.
+//Ties between the best nodes go to the node whose name sorts first, so simulations are repeatable
+type Simulator struct {
+	predicates     map[string]algorithm.FitPredicate
+	priorities     []algorithm.PriorityConfig
+	nodes          []*api.Node
+	pods           []*api.Pod
+	nodeNameToInfo map[string]*schedulercache.NodeInfo
+}
+
+// simulatedPods lists the pods placed in the simulation
+type simulatedPods struct {
+	simulator *Simulator
+}
+
+func (p simulatedPods) List(selector labels.Selector) ([]*api.Pod, error) {
+	pods := []*api.Pod{}
+	for _, pod := range p.simulator.pods {
+		if selector.Matches(labels.Set(pod.Labels)) {
+			pods = append(pods, pod)
+		}
+	}
+	return pods, nil
+}
+
+// simulatedNodes lists the nodes of the simulation
+type simulatedNodes struct {
+	simulator *Simulator
+}
+
+func (n simulatedNodes) List() (api.NodeList, error) {
+	list := api.NodeList{}
+	for _, node := range n.simulator.nodes {
+		list.Items = append(list.Items, *node)
+	}
+	return list, nil
+}
+
+func (n simulatedNodes) GetNodeInfo(name string) (*api.Node, error) {
+	info, exists := n.simulator.nodeNameToInfo[name]
+	if !exists || info.Node() == nil {
+		return nil, fmt.Errorf("Unknown node %s", name)
+	}
+	return info.Node(), nil
+}
+
+//NewSimulator creates a simulator for 'nodes' with 'pods' already assigned to them. 'predicates' and the
+//keys of 'priorities' are names registered by this package, the values of 'priorities' are their weights
+func NewSimulator(nodes []*api.Node, pods []*api.Pod, predicates []string, priorities map[string]int) (*Simulator, error) {
+	s := &Simulator{
+		predicates:     map[string]algorithm.FitPredicate{},
+		nodes:          append([]*api.Node{}, nodes...),
+		pods:           []*api.Pod{},
+		nodeNameToInfo: schedulercache.CreateNodeNameToInfoMap(pods, nodes),
+	}
+	sort.Sort(nodesByName(s.nodes))
+
+	for _, pod := range pods {
+		if pod.Spec.NodeName != "" {
+			s.pods = append(s.pods, pod)
+		}
+	}
+
+	args := factory.PluginFactoryArgs{
+		PodLister:  simulatedPods{s},
+		NodeLister: simulatedNodes{s},
+		NodeInfo:   simulatedNodes{s},
+	}
+	for _, name := range predicates {
+		predicateFactory, exists := predicateFactories[name]
+		if !exists {
+			return nil, fmt.Errorf("Unknown predicate %s", name)
+		}
+		s.predicates[name] = predicateFactory(args)
+	}
+
+	names := []string{}
+	for name := range priorities {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		priorityFactory, exists := priorityFactories[name]
+		if !exists {
+			return nil, fmt.Errorf("Unknown priority %s", name)
+		}
+		if priorities[name] <= 0 {
+			return nil, fmt.Errorf("Weight of priority %s must be positive: %d", name, priorities[name])
+		}
+		s.priorities = append(s.priorities, algorithm.PriorityConfig{
+			Function: priorityFactory.Function(args),
+			Weight:   priorities[name],
+		})
+	}
+
+	return s, nil
+}
+
+//Place places the pod on the best node it fits on, if any
+func (s *Simulator) Place(pod *api.Pod) (SimulatedPlacement, error) {
+	placement := SimulatedPlacement{
+		Pod:      pod,
+		Failures: map[string]int{},
+	}
+
+	fitting := []*api.Node{}
+	for _, node := range s.nodes {
+		fits := true
+		for _, predicate := range s.predicates {
+			fit, reasons, err := predicate(pod, nil, s.nodeNameToInfo[node.Name])
+			if err != nil {
+				return placement, err
+			}
+			for _, reason := range reasons {
+				placement.Failures[failureReason(reason)]++
+			}
+			fits = fits && fit
+		}
+		if fits {
+			fitting = append(fitting, node)
+		}
+	}
+	if len(fitting) == 0 {
+		return placement, nil
+	}
+
+	scores := map[string]int{}
+	for _, priority := range s.priorities {
+		list, err := priority.Function(pod, s.nodeNameToInfo, fitting)
+		if err != nil {
+			return placement, err
+		}
+		for _, hostPriority := range list {
+			scores[hostPriority.Host] += hostPriority.Score * priority.Weight
+		}
+	}
+
+	best := fitting[0].Name
+	for _, node := range fitting {
+		if scores[node.Name] > scores[best] {
+			best = node.Name
+		}
+	}
+
+	placed := *pod
+	placed.Spec.NodeName = best
+	s.nodeNameToInfo[best].AddPod(&placed)
+	s.pods = append(s.pods, &placed)
+
+	placement.Pod = &placed
+	placement.Node = best
+	return placement, nil
+}
+
+//Simulate places the pods in order
+func (s *Simulator) Simulate(pods []*api.Pod) ([]SimulatedPlacement, error) {
+	placements := []SimulatedPlacement{}
+	for _, pod := range pods {
+		placement, err := s.Place(pod)
+		if err != nil {
+			return nil, fmt.Errorf("Unable to place Pod %s/%s: %v", pod.Namespace, pod.Name, err)
+		}
+		placements = append(placements, placement)
+	}
+	return placements, nil
+}
+
+//Utilization returns the resources used on every node, sorted by node name
+func (s *Simulator) Utilization() []NodeUtilization {
+	usage := getClusterUsage(s.nodes, s.pods)
+
+	utilization := []NodeUtilization{}
+	for _, node := range s.nodes {
+		utilization = append(utilization, NodeUtilization{
+			Node:      node.Name,
+			Pods:      len(s.nodeNameToInfo[node.Name].Pods()),
+			Requested: usage.requested[node.Name],
+			Capacity:  usage.capacity[node.Name],
+		})
+	}
+	return utilization
+}
+
+//EmptyNodes returns the number of nodes without any pods
+func (s *Simulator) EmptyNodes() int {
+	empty := 0
+	for _, node := range s.nodes {
+		if len(s.nodeNameToInfo[node.Name].Pods()) == 0 {
+			empty++
+		}
+	}
+	return empty
+}
+
+type nodesByName []*api.Node
+
+func (n nodesByName) Len() int           { return len(n) }
+func (n nodesByName) Less(i, j int) bool { return n[i].Name < n[j].Name }
+func (n nodesByName) Swap(i, j int)      { n[i], n[j] = n[j], n[i] }
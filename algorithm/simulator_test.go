@@ -0,0 +1,125 @@
+package algorithm
+
+import (
+	"testing"
+
+	"k8s.io/kubernetes/pkg/api"
+)
+
+func createSimulatedNode(name string, milliCPU, memory int64) *api.Node {
+	node := createResourceNode(milliCPU, memory, 0, 10)
+	node.Name = name
+	return node
+}
+
+func createSimulatedPod(name string, milliCPU, memory int64) *api.Pod {
+	pod := createResourcePod(milliCPU, memory, 0)
+	pod.Namespace = "default"
+	pod.Name = name
+	return pod
+}
+
+func TestSimulator(t *testing.T) {
+	nodes := []*api.Node{
+		createSimulatedNode("node-c", 4000, 10000),
+		createSimulatedNode("node-a", 4000, 10000),
+		createSimulatedNode("node-b", 4000, 10000),
+	}
+	running := createSimulatedPod("running", 2000, 2000)
+	running.Spec.NodeName = "node-b"
+
+	pending := []*api.Pod{
+		createSimulatedPod("first", 1000, 1000),
+		createSimulatedPod("second", 1000, 1000),
+		createSimulatedPod("large", 3000, 1000),
+		createSimulatedPod("huge", 5000, 1000),
+	}
+
+	simulator, err := NewSimulator(nodes, []*api.Pod{running}, []string{podOverCommitNodePred, nodeOutOfDiskPred}, map[string]int{"MostUsed": 1})
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	placements, err := simulator.Simulate(pending)
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	expected := []string{"node-b", "node-b", "node-a", ""}
+	for i, placement := range placements {
+		if placement.Node != expected[i] {
+			t.Errorf("Pod %s. Expected: %q Actual: %q", placement.Pod.Name, expected[i], placement.Node)
+		}
+	}
+	if failures := placements[3].Failures["PodOverCommitNode-CPU"]; failures != 3 {
+		t.Errorf("Expected 3 nodes rejected for CPU, got %d: %v", failures, placements[3].Failures)
+	}
+	if pending[0].Spec.NodeName != "" {
+		t.Errorf("Expected the pending pods to be left unchanged")
+	}
+
+	utilization := simulator.Utilization()
+	if len(utilization) != 3 || utilization[0].Node != "node-a" || utilization[1].Node != "node-b" {
+		t.Fatalf("Expected the utilization sorted by node: %+v", utilization)
+	}
+	if utilization[1].Pods != 3 || utilization[1].Requested[api.ResourceCPU] != 4000 || utilization[1].Capacity[api.ResourceCPU] != 4000 {
+		t.Errorf("Unexpected utilization of node-b: %+v", utilization[1])
+	}
+	if empty := simulator.EmptyNodes(); empty != 1 {
+		t.Errorf("Expected 1 empty node, got %d", empty)
+	}
+}
+
+func TestSimulatorTopologySpread(t *testing.T) {
+	defer func(policies []AppSpreadingPolicy) { packingPolicy.AppSpreading = policies }(packingPolicy.AppSpreading)
+	packingPolicy.AppSpreading = []AppSpreadingPolicy{
+		{
+			AppIdentity:  AppIdentity{Name: "web", Selector: "app=web"},
+			TopologyKey:  "zone",
+			MaxPerDomain: 1,
+		},
+	}
+
+	nodes := []*api.Node{
+		createSimulatedNode("node-a", 4000, 10000),
+		createSimulatedNode("node-b", 4000, 10000),
+		createSimulatedNode("node-c", 4000, 10000),
+	}
+	nodes[0].Labels = map[string]string{"zone": "east"}
+	nodes[1].Labels = map[string]string{"zone": "east"}
+	nodes[2].Labels = map[string]string{"zone": "west"}
+
+	pending := []*api.Pod{}
+	for _, name := range []string{"web-1", "web-2", "web-3"} {
+		pod := createSimulatedPod(name, 1000, 1000)
+		pod.Labels = map[string]string{"app": "web"}
+		pending = append(pending, pod)
+	}
+
+	simulator, err := NewSimulator(nodes, nil, []string{appTopologySpreadPred}, map[string]int{"MostUsed": 1})
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	placements, err := simulator.Simulate(pending)
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	expected := []string{"node-a", "node-c", ""}
+	for i, placement := range placements {
+		if placement.Node != expected[i] {
+			t.Errorf("Pod %s. Expected: %q Actual: %q", placement.Pod.Name, expected[i], placement.Node)
+		}
+	}
+}
+
+func TestNewSimulatorUnknown(t *testing.T) {
+	if _, err := NewSimulator(nil, nil, []string{"PodFitsMagic"}, nil); err == nil {
+		t.Errorf("Expected an error for an unknown predicate")
+	}
+	if _, err := NewSimulator(nil, nil, nil, map[string]int{"MostMagic": 1}); err == nil {
+		t.Errorf("Expected an error for an unknown priority")
+	}
+	if _, err := NewSimulator(nil, nil, nil, map[string]int{"MostUsed": 0}); err == nil {
+		t.Errorf("Expected an error for a priority without weight")
+	}
+}
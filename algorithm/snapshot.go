@@ -0,0 +1,206 @@
+package algorithm
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+
+	"github.com/ghodss/yaml"
+	"k8s.io/kubernetes/pkg/api"
+	_ "k8s.io/kubernetes/pkg/api/install"
+	"k8s.io/kubernetes/pkg/api/unversioned"
+	"k8s.io/kubernetes/pkg/api/v1"
+	"k8s.io/kubernetes/pkg/runtime"
+	"k8s.io/kubernetes/plugin/pkg/scheduler/schedulercache"
+)
+
+//SnapshotVersion is the version of the snapshot format written by WriteSnapshot
+const SnapshotVersion = "packscheduler/v1"
+
+//Snapshot is a capture of the nodes the scheduler sees and the pods assigned to each of them
+type Snapshot struct {
+	Version string           `json:"version"`
+	Time    unversioned.Time `json:"time"`
+	Nodes   []SnapshotNode   `json:"nodes"`
+}
+
+//SnapshotNode is a node and the pods assigned to it
+type SnapshotNode struct {
+	Node api.Node  `json:"node"`
+	Pods []api.Pod `json:"pods,omitempty"`
+}
+
+//NewSnapshot captures the nodes and pods of the NodeInfos. Pods assigned to unknown nodes are left out
+func NewSnapshot(nodeNameToInfo map[string]*schedulercache.NodeInfo) *Snapshot {
+	snapshot := &Snapshot{
+		Version: SnapshotVersion,
+		Time:    unversioned.Now(),
+		Nodes:   []SnapshotNode{},
+	}
+
+	names := []string{}
+	for name, info := range nodeNameToInfo {
+		if info.Node() != nil {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		info := nodeNameToInfo[name]
+		node := SnapshotNode{Node: *info.Node()}
+		for _, pod := range info.Pods() {
+			node.Pods = append(node.Pods, *pod)
+		}
+		sort.Sort(podsByName(node.Pods))
+		snapshot.Nodes = append(snapshot.Nodes, node)
+	}
+	return snapshot
+}
+
+//NodeNameToInfo rebuilds the NodeInfo of every node in the snapshot
+func (s *Snapshot) NodeNameToInfo() map[string]*schedulercache.NodeInfo {
+	return schedulercache.CreateNodeNameToInfoMap(s.Pods(), s.NodeList())
+}
+
+//NodeList returns the nodes of the snapshot
+func (s *Snapshot) NodeList() []*api.Node {
+	nodes := []*api.Node{}
+	for i := range s.Nodes {
+		nodes = append(nodes, &s.Nodes[i].Node)
+	}
+	return nodes
+}
+
+//Pods returns the pods of every node in the snapshot
+func (s *Snapshot) Pods() []*api.Pod {
+	pods := []*api.Pod{}
+	for i := range s.Nodes {
+		for j := range s.Nodes[i].Pods {
+			pods = append(pods, &s.Nodes[i].Pods[j])
+		}
+	}
+	return pods
+}
+
+// snapshotFile is a Snapshot as it is written, with the nodes and pods encoded as v1 objects. The internal
+// types leave out fields like the init containers of pods when encoded as JSON. Like every v1 pod, the pods
+// of a snapshot carry their init containers in the pod.alpha.kubernetes.io/init-containers annotation, as
+// spec.initContainers is not part of the v1 API
+type snapshotFile struct {
+	Version string             `json:"version"`
+	Time    unversioned.Time   `json:"time"`
+	Nodes   []snapshotFileNode `json:"nodes"`
+}
+
+type snapshotFileNode struct {
+	Node json.RawMessage   `json:"node"`
+	Pods []json.RawMessage `json:"pods,omitempty"`
+}
+
+//SnapshotSchedulerCache captures the nodes and pods of the running scheduler cache, including the pods it assumed
+//but has not bound yet. 'ok' is false until the scheduler uses a predicate or priority of this package
+func SnapshotSchedulerCache() (snapshot *Snapshot, ok bool, err error) {
+	nodes, pods, ok, err := ListSchedulerCache()
+	if !ok || err != nil {
+		return nil, ok, err
+	}
+	return NewSnapshot(schedulercache.CreateNodeNameToInfoMap(pods, nodes)), true, nil
+}
+
+//WriteSnapshot writes the snapshot as indented JSON, with the nodes and pods as v1 objects
+func WriteSnapshot(out io.Writer, snapshot *Snapshot) error {
+	codec := api.Codecs.LegacyCodec(v1.SchemeGroupVersion)
+	file := snapshotFile{
+		Version: snapshot.Version,
+		Time:    snapshot.Time,
+		Nodes:   []snapshotFileNode{},
+	}
+	for i := range snapshot.Nodes {
+		node, err := runtime.Encode(codec, &snapshot.Nodes[i].Node)
+		if err != nil {
+			return fmt.Errorf("Unable to encode node %s: %v", snapshot.Nodes[i].Node.Name, err)
+		}
+
+		fileNode := snapshotFileNode{Node: node}
+		for j := range snapshot.Nodes[i].Pods {
+			pod := &snapshot.Nodes[i].Pods[j]
+			data, err := runtime.Encode(codec, pod)
+			if err != nil {
+				return fmt.Errorf("Unable to encode Pod %s/%s: %v", pod.Namespace, pod.Name, err)
+			}
+			fileNode.Pods = append(fileNode.Pods, data)
+		}
+		file.Nodes = append(file.Nodes, fileNode)
+	}
+
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = out.Write(append(data, '\n'))
+	return err
+}
+
+//ReadSnapshot reads a snapshot written as JSON or YAML, checking it uses a known version of the format.
+//Nodes and pods are v1 objects and may leave out their apiVersion and kind. Init containers are read from the
+//pod.alpha.kubernetes.io/init-containers annotation. Pods are assigned to the node they are listed under
+func ReadSnapshot(in io.Reader) (*Snapshot, error) {
+	data, err := ioutil.ReadAll(in)
+	if err != nil {
+		return nil, err
+	}
+	if data, err = yaml.YAMLToJSON(data); err != nil {
+		return nil, fmt.Errorf("Unable to parse snapshot: %v", err)
+	}
+
+	file := &snapshotFile{}
+	if err := json.Unmarshal(data, file); err != nil {
+		return nil, fmt.Errorf("Unable to parse snapshot: %v", err)
+	}
+	if file.Version != SnapshotVersion {
+		return nil, fmt.Errorf("Unsupported snapshot version %q, expected %q", file.Version, SnapshotVersion)
+	}
+
+	snapshot := &Snapshot{
+		Version: file.Version,
+		Time:    file.Time,
+		Nodes:   make([]SnapshotNode, len(file.Nodes)),
+	}
+	for i, fileNode := range file.Nodes {
+		node := &snapshot.Nodes[i]
+		if err := decodeSnapshotObject(fileNode.Node, "Node", &node.Node); err != nil {
+			return nil, fmt.Errorf("Unable to parse node %d of the snapshot: %v", i, err)
+		}
+
+		node.Pods = make([]api.Pod, len(fileNode.Pods))
+		for j, data := range fileNode.Pods {
+			if err := decodeSnapshotObject(data, "Pod", &node.Pods[j]); err != nil {
+				return nil, fmt.Errorf("Unable to parse pod %d of node %s: %v", j, node.Node.Name, err)
+			}
+			node.Pods[j].Spec.NodeName = node.Node.Name
+		}
+	}
+	return snapshot, nil
+}
+
+// decodeSnapshotObject decodes a v1 object of the snapshot into its internal type, defaulting to 'kind' when
+// the object leaves out its apiVersion and kind
+func decodeSnapshotObject(data []byte, kind string, into runtime.Object) error {
+	defaults := v1.SchemeGroupVersion.WithKind(kind)
+	_, _, err := api.Codecs.UniversalDecoder().Decode(data, &defaults, into)
+	return err
+}
+
+type podsByName []api.Pod
+
+func (p podsByName) Len() int { return len(p) }
+func (p podsByName) Less(i, j int) bool {
+	if p[i].Namespace != p[j].Namespace {
+		return p[i].Namespace < p[j].Namespace
+	}
+	return p[i].Name < p[j].Name
+}
+func (p podsByName) Swap(i, j int) { p[i], p[j] = p[j], p[i] }
@@ -0,0 +1,149 @@
+package algorithm
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	"k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/plugin/pkg/scheduler/algorithm"
+	"k8s.io/kubernetes/plugin/pkg/scheduler/factory"
+	"k8s.io/kubernetes/plugin/pkg/scheduler/schedulercache"
+)
+
+// Three m4.large nodes captured from a small cluster. ip-10-0-1-10 is nearly full, ip-10-0-1-11 is
+// half used and ip-10-0-2-12 only runs kube-proxy.
+const testSnapshot = "testdata/snapshot.json"
+
+func loadTestSnapshot(t *testing.T) *Snapshot {
+	file, err := os.Open(testSnapshot)
+	if err != nil {
+		t.Fatalf("Unable to open %s: %v", testSnapshot, err)
+	}
+	defer file.Close()
+
+	snapshot, err := ReadSnapshot(file)
+	if err != nil {
+		t.Fatalf("Unable to read %s: %v", testSnapshot, err)
+	}
+	return snapshot
+}
+
+func TestReadSnapshot(t *testing.T) {
+	nodeNameToInfo := loadTestSnapshot(t).NodeNameToInfo()
+
+	expected := map[string]int{
+		"ip-10-0-1-10.ec2.internal": 3,
+		"ip-10-0-1-11.ec2.internal": 2,
+		"ip-10-0-2-12.ec2.internal": 1,
+	}
+	if len(nodeNameToInfo) != len(expected) {
+		t.Fatalf("Expected %d nodes, got %d", len(expected), len(nodeNameToInfo))
+	}
+	for name, pods := range expected {
+		info, exists := nodeNameToInfo[name]
+		if !exists || info.Node() == nil {
+			t.Errorf("Expected node %s in the snapshot", name)
+			continue
+		}
+		if len(info.Pods()) != pods {
+			t.Errorf("Node %s. Expected %d pods, got %d", name, pods, len(info.Pods()))
+		}
+		for _, pod := range info.Pods() {
+			if pod.Spec.NodeName != name {
+				t.Errorf("Expected pod %s to be assigned to %s, got %q", pod.Name, name, pod.Spec.NodeName)
+			}
+		}
+	}
+
+	node := nodeNameToInfo["ip-10-0-1-10.ec2.internal"].Node()
+	if capacity := getCapacity(api.ResourceCPU, node); capacity != 1900 {
+		t.Errorf("Expected the allocatable CPU of 1900, got %d", capacity)
+	}
+	if len(node.Status.Conditions) != 4 || node.Status.Conditions[0].LastHeartbeatTime.IsZero() {
+		t.Errorf("Expected the node conditions with their heartbeats: %+v", node.Status.Conditions)
+	}
+}
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	running := createResourcePod(1000, 1000, 0)
+	running.Name = "running"
+	running.Spec.NodeName = "machine1"
+	running.Spec.InitContainers = []api.Container{{Name: "migrate"}}
+	nodeNameToInfo := schedulercache.CreateNodeNameToInfoMap([]*api.Pod{running}, []*api.Node{createResourceNode(4000, 10000, 0, 10)})
+
+	out := &bytes.Buffer{}
+	if err := WriteSnapshot(out, NewSnapshot(nodeNameToInfo)); err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	snapshot, err := ReadSnapshot(out)
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	info := snapshot.NodeNameToInfo()["machine1"]
+	if info == nil || info.Node() == nil || len(info.Pods()) != 1 || info.Pods()[0].Name != "running" {
+		t.Fatalf("Expected machine1 with its pod in the snapshot: %+v", snapshot)
+	}
+	if capacity := getCapacity(api.ResourceCPU, info.Node()); capacity != 4000 {
+		t.Errorf("Expected the CPU capacity to survive the round trip, got %d", capacity)
+	}
+	if requested := getResourcesForPod(info.Pods()[0], info.Node())[api.ResourceCPU]; requested != 1000 {
+		t.Errorf("Expected the CPU request to survive the round trip, got %d", requested)
+	}
+	if init := info.Pods()[0].Spec.InitContainers; len(init) != 1 || init[0].Name != "migrate" {
+		t.Errorf("Expected the init containers to survive the round trip, got %+v", init)
+	}
+}
+
+func TestSnapshotSchedulerCache(t *testing.T) {
+	defer func(listers *factory.PluginFactoryArgs) { schedulerListers = listers }(schedulerListers)
+	schedulerListers = nil
+
+	if _, ok, _ := SnapshotSchedulerCache(); ok {
+		t.Fatalf("Expected no snapshot before the scheduler builds a predicate")
+	}
+
+	assumed := createSimulatedPod("assumed", 1000, 1000)
+	assumed.Spec.NodeName = "node-a"
+	recordSchedulerListers(factory.PluginFactoryArgs{
+		PodLister:  algorithm.FakePodLister{assumed},
+		NodeLister: testNodeLister{createSimulatedNode("node-a", 4000, 10000)},
+	})
+
+	snapshot, ok, err := SnapshotSchedulerCache()
+	if !ok || err != nil {
+		t.Fatalf("Expected a snapshot of the scheduler cache, got %t %v", ok, err)
+	}
+	if len(snapshot.Nodes) != 1 || len(snapshot.Nodes[0].Pods) != 1 || snapshot.Nodes[0].Pods[0].Name != "assumed" {
+		t.Errorf("Expected node-a with the assumed pod in the snapshot: %+v", snapshot)
+	}
+}
+
+func TestReadSnapshotVersion(t *testing.T) {
+	if _, err := ReadSnapshot(strings.NewReader(`{"version": "packscheduler/v0", "nodes": []}`)); err == nil {
+		t.Errorf("Expected an error for an unknown snapshot version")
+	}
+}
+
+func TestSimulatorSnapshot(t *testing.T) {
+	snapshot := loadTestSnapshot(t)
+	simulator, err := NewSimulator(snapshot.NodeList(), snapshot.Pods(), []string{podOverCommitNodePred, nodeOutOfDiskPred}, map[string]int{"MostUsed": 1})
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	pod := createSimulatedPod("web", 500, 512*1024*1024)
+	placement, err := simulator.Place(pod)
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	if placement.Node != "ip-10-0-1-11.ec2.internal" {
+		t.Errorf("Expected the pod on the half used node, got %q", placement.Node)
+	}
+	if placement.Failures["PodOverCommitNode-CPU"] != 1 {
+		t.Errorf("Expected the nearly full node to be rejected for CPU: %v", placement.Failures)
+	}
+}
@@ -0,0 +1,144 @@
+package algorithm
+
+import (
+	"fmt"
+
+	"github.com/golang/glog"
+	"k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/labels"
+	"k8s.io/kubernetes/plugin/pkg/scheduler/algorithm"
+	schedulerapi "k8s.io/kubernetes/plugin/pkg/scheduler/api"
+	"k8s.io/kubernetes/plugin/pkg/scheduler/schedulercache"
+)
+
+const (
+	// Annotation with the SpotPreference of a pod
+	spotPreferenceAnnotation = "packscheduler.alpha.kubernetes.io/spot"
+	// Label selecting spot and preemptible nodes, unless the packing policy sets another
+	defaultSpotNodeSelector = "packscheduler.alpha.kubernetes.io/spot=true"
+)
+
+// spotNodes is a SpotPolicy ready to match nodes and pods against
+type spotNodes struct {
+	selector          labels.Selector
+	defaultPreference SpotPreference
+}
+
+func newSpotNodes(policy SpotPolicy) (*spotNodes, error) {
+	selector, err := labels.Parse(policy.Selector)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid spot node selector %q: %v", policy.Selector, err)
+	}
+	if selector.Empty() {
+		return nil, fmt.Errorf("Spot node selector must not be empty")
+	}
+
+	switch policy.Default {
+	case SpotForbid, SpotTolerate, SpotPrefer:
+	default:
+		return nil, fmt.Errorf("Unknown default spot preference: %q", policy.Default)
+	}
+
+	return &spotNodes{selector: selector, defaultPreference: policy.Default}, nil
+}
+
+func (s *spotNodes) isSpot(node *api.Node) bool {
+	return s.selector.Matches(labels.Set(node.Labels))
+}
+
+// preference returns the spot preference the pod declares, or the default when it declares none or an unknown one
+func (s *spotNodes) preference(pod *api.Pod) SpotPreference {
+	value, exists := pod.Annotations[spotPreferenceAnnotation]
+	if !exists {
+		return s.defaultPreference
+	}
+
+	switch preference := SpotPreference(value); preference {
+	case SpotForbid, SpotTolerate, SpotPrefer:
+		return preference
+	}
+	glog.V(2).Infof("Ignoring unknown spot preference %q of Pod %s/%s", value, pod.Namespace, pod.Name)
+	return s.defaultPreference
+}
+
+//NewSpotNodePredicate creates a predicate rejecting spot and preemptible nodes for the pods that forbid them
+func NewSpotNodePredicate(policy SpotPolicy) (algorithm.FitPredicate, error) {
+	spot, err := newSpotNodes(policy)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(pod *api.Pod, meta interface{}, cacheInfo *schedulercache.NodeInfo) (bool, []algorithm.PredicateFailureReason, error) {
+		info := cacheInfo.Node()
+		if spot.preference(pod) == SpotForbid && spot.isSpot(info) {
+			glog.V(10).Infof("Cannot schedule Pod %s, Because Node %v is a spot node", pod.Name, info.Name)
+			return false, []algorithm.PredicateFailureReason{spotNodePredError}, nil
+		}
+		return true, nil, nil
+	}, nil
+}
+
+//NewSpotMostRequestedPriority creates a priority that packs pods by their MostUsed occupancy and ranks spot nodes by the
+//preference of the pod. Pods that prefer spot nodes fill them before any other node, pods that forbid them rank them
+//below every other node. Pods that tolerate spot nodes give them a smaller boost, so spot capacity fills first unless
+//another node is much fuller
+func NewSpotMostRequestedPriority(policy SpotPolicy, scoring ScoreConfig) (algorithm.PriorityFunction, error) {
+	spot, err := newSpotNodes(policy)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(pod *api.Pod, nodeNameToInfo map[string]*schedulercache.NodeInfo, nodes []*api.Node) (schedulerapi.HostPriorityList, error) {
+		preference := spot.preference(pod)
+
+		list := schedulerapi.HostPriorityList{}
+		for _, node := range nodes {
+			occupancy := calculateResourceOccupancy(pod, node, nodeNameToInfo[node.Name].Pods(), defaultResourceWeights, scoring.MaxScore)
+			if occupancy.Score == 0 {
+				list = append(list, occupancy)
+				continue
+			}
+
+			var score int
+			if preference == SpotTolerate {
+				score = spotBoostScore(occupancy.Score, spot.isSpot(node), scoring.MaxScore)
+			} else {
+				preferred := spot.isSpot(node) == (preference == SpotPrefer)
+				score = spotBandScore(occupancy.Score, preferred, scoring.MaxScore)
+			}
+			glog.V(10).Infof(
+				"%v -> %v: Spot Most Requested Priority, Preference: %s Spot: %t Occupancy: %d Score: %d",
+				pod.Name, node.Name,
+				preference, spot.isSpot(node),
+				occupancy.Score, score,
+			)
+
+			list = append(list, schedulerapi.HostPriority{Host: node.Name, Score: score})
+		}
+		if scoring.Normalize {
+			normalizeScores(list)
+		}
+		return list, nil
+	}, nil
+}
+
+// spotBandScore scales an occupancy score into the upper half of 1-maxScore for preferred nodes and the lower
+// half for the others, so any preferred node outranks every other node
+func spotBandScore(occupancy int, preferred bool, maxScore int) int {
+	half := maxScore / 2
+	if preferred {
+		return half + 1 + occupancy*(maxScore-half-1)/maxScore
+	}
+	return 1 + occupancy*(half-1)/maxScore
+}
+
+// spotBoostScore scales an occupancy score into 1-maxScore leaving room for a boost of a fifth of maxScore
+// that spot nodes get, so a spot node outranks other nodes up to a fifth of maxScore fuller than it
+func spotBoostScore(occupancy int, spot bool, maxScore int) int {
+	boost := maxScore / 5
+	score := 1 + occupancy*(maxScore-boost-1)/maxScore
+	if spot {
+		score += boost
+	}
+	return score
+}
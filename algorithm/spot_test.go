@@ -0,0 +1,161 @@
+package algorithm
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/kubernetes/pkg/api"
+	schedulerapi "k8s.io/kubernetes/plugin/pkg/scheduler/api"
+	"k8s.io/kubernetes/plugin/pkg/scheduler/schedulercache"
+)
+
+var testSpotPolicy = SpotPolicy{Selector: "lifecycle=spot", Default: SpotTolerate}
+
+func makeSpotNode(node string, milliCPU, memory int64) *api.Node {
+	n := makeNode(node, milliCPU, memory)
+	n.Labels = map[string]string{"lifecycle": "spot"}
+	return n
+}
+
+func createSpotPod(preference string) *api.Pod {
+	pod := createResourcePod(1000, 1000, 0)
+	if preference != "" {
+		pod.Annotations = map[string]string{spotPreferenceAnnotation: preference}
+	}
+	return pod
+}
+
+func TestSpotNodePredicate(t *testing.T) {
+	predicate, err := NewSpotNodePredicate(testSpotPolicy)
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	tests := []struct {
+		testName string
+		pod      *api.Pod
+		node     *api.Node
+		fits     bool
+	}{
+		{"ForbidSpot", createSpotPod("forbid"), makeSpotNode("machine1", 10000, 10000), false},
+		{"ForbidOnDemand", createSpotPod("forbid"), makeNode("machine1", 10000, 10000), true},
+		{"TolerateSpot", createSpotPod("tolerate"), makeSpotNode("machine1", 10000, 10000), true},
+		{"PreferSpot", createSpotPod("prefer"), makeSpotNode("machine1", 10000, 10000), true},
+		{"DefaultSpot", createSpotPod(""), makeSpotNode("machine1", 10000, 10000), true},
+	}
+
+	for _, test := range tests {
+		info := schedulercache.NewNodeInfo()
+		info.SetNode(test.node)
+
+		fits, reasons, err := predicate(test.pod, nil, info)
+		if err != nil {
+			t.Errorf("Test %s. Unexpected error %v", test.testName, err)
+			continue
+		}
+		if fits != test.fits {
+			t.Errorf("Test %s. Expected fit %t, got %t", test.testName, test.fits, fits)
+		}
+		if !test.fits && !hasReason(reasons, spotNodePred) {
+			t.Errorf("Test %s. Expected the %s failure, got %v", test.testName, spotNodePred, reasons)
+		}
+	}
+}
+
+func TestSpotMostRequested(t *testing.T) {
+	existingPod := createResourcePod(5000, 5000, 0)
+	existingPod.Spec.NodeName = "machine1"
+	nodes := []*api.Node{makeNode("machine1", 10000, 10000), makeSpotNode("machine2", 10000, 10000)}
+
+	tests := []struct {
+		pod          *api.Pod
+		policy       SpotPolicy
+		existing     []*api.Pod
+		expectedList schedulerapi.HostPriorityList
+		test         string
+	}{
+		{
+			/*
+				Node1 occupancy: 7, not preferred: 1 + 7 * 4 / 10 = 3
+				Node2 occupancy: 2, preferred: 6 + 2 * 4 / 10 = 6
+			*/
+			pod:          createSpotPod("prefer"),
+			policy:       testSpotPolicy,
+			expectedList: []schedulerapi.HostPriority{{Host: "machine1", Score: 3}, {Host: "machine2", Score: 6}},
+			test:         "prefer fills the spot node first",
+		},
+		{
+			/*
+				Node1 occupancy: 7, not spot: 1 + 7 * 7 / 10 = 5
+				Node2 occupancy: 2, spot: 1 + 2 * 7 / 10 + 2 = 4
+			*/
+			pod:          createSpotPod("tolerate"),
+			policy:       testSpotPolicy,
+			expectedList: []schedulerapi.HostPriority{{Host: "machine1", Score: 5}, {Host: "machine2", Score: 4}},
+			test:         "tolerate packs a much fuller node first",
+		},
+		{
+			/*
+				Node1 occupancy: 2, not spot: 1 + 2 * 7 / 10 = 2
+				Node2 occupancy: 2, spot: 1 + 2 * 7 / 10 + 2 = 4
+			*/
+			pod:          createSpotPod("tolerate"),
+			policy:       testSpotPolicy,
+			existing:     []*api.Pod{},
+			expectedList: []schedulerapi.HostPriority{{Host: "machine1", Score: 2}, {Host: "machine2", Score: 4}},
+			test:         "tolerate fills the spot node first",
+		},
+		{
+			/*
+				Node1 occupancy: 7, preferred: 6 + 7 * 4 / 10 = 8
+				Node2 occupancy: 2, not preferred: 1 + 2 * 4 / 10 = 1
+			*/
+			pod:          createSpotPod("forbid"),
+			policy:       testSpotPolicy,
+			expectedList: []schedulerapi.HostPriority{{Host: "machine1", Score: 8}, {Host: "machine2", Score: 1}},
+			test:         "forbid ranks the spot node last",
+		},
+		{
+			pod:          createSpotPod(""),
+			policy:       SpotPolicy{Selector: "lifecycle=spot", Default: SpotPrefer},
+			expectedList: []schedulerapi.HostPriority{{Host: "machine1", Score: 3}, {Host: "machine2", Score: 6}},
+			test:         "default preference",
+		},
+		{
+			pod:          createSpotPod("always"),
+			policy:       testSpotPolicy,
+			expectedList: []schedulerapi.HostPriority{{Host: "machine1", Score: 5}, {Host: "machine2", Score: 4}},
+			test:         "unknown preference uses the default",
+		},
+	}
+
+	for _, test := range tests {
+		priority, err := NewSpotMostRequestedPriority(test.policy, defaultScoreConfig)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", test.test, err)
+		}
+		existing := test.existing
+		if existing == nil {
+			existing = []*api.Pod{existingPod}
+		}
+		list, err := priority(test.pod, schedulercache.CreateNodeNameToInfoMap(existing, nodes), nodes)
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if !reflect.DeepEqual(test.expectedList, list) {
+			t.Errorf("%s: expected %#v, got %#v", test.test, test.expectedList, list)
+		}
+	}
+}
+
+func TestNewSpotNodesInvalid(t *testing.T) {
+	policies := []SpotPolicy{
+		{Selector: "", Default: SpotTolerate},
+		{Selector: "lifecycle=spot", Default: "sometimes"},
+	}
+	for _, policy := range policies {
+		if _, err := newSpotNodes(policy); err == nil {
+			t.Errorf("Expected an error for %+v", policy)
+		}
+	}
+}
@@ -0,0 +1,183 @@
+package algorithm
+
+import (
+	"fmt"
+
+	"github.com/golang/glog"
+	"k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/plugin/pkg/scheduler/algorithm"
+	pluginPred "k8s.io/kubernetes/plugin/pkg/scheduler/algorithm/predicates"
+	schedulerapi "k8s.io/kubernetes/plugin/pkg/scheduler/api"
+	"k8s.io/kubernetes/plugin/pkg/scheduler/schedulercache"
+)
+
+// appSpreading is an AppSpreadingPolicy ready to match pods against
+type appSpreading struct {
+	*appIdentity
+	topologyKey  string
+	maxPerDomain int
+	failure      *pluginPred.PredicateFailureError
+}
+
+func newAppSpreading(policy AppSpreadingPolicy) (*appSpreading, error) {
+	identity, err := newAppIdentity(policy.AppIdentity)
+	if err != nil {
+		return nil, err
+	}
+
+	if policy.TopologyKey == "" {
+		return nil, fmt.Errorf("App spreading policy %s needs a topology key", identity.name)
+	}
+	if policy.MaxPerDomain < 0 {
+		return nil, fmt.Errorf("App spreading policy %s must not have a negative max per domain: %d", identity.name, policy.MaxPerDomain)
+	}
+
+	return &appSpreading{
+		appIdentity:  identity,
+		topologyKey:  policy.TopologyKey,
+		maxPerDomain: policy.MaxPerDomain,
+		failure:      newPredicateFailure(fmt.Sprintf("%s-%s", appTopologySpreadPred, identity.name)),
+	}, nil
+}
+
+func newAppSpreadings(policies []AppSpreadingPolicy) ([]*appSpreading, error) {
+	spreadings := []*appSpreading{}
+	for _, policy := range policies {
+		spreading, err := newAppSpreading(policy)
+		if err != nil {
+			return nil, err
+		}
+		spreadings = append(spreadings, spreading)
+	}
+	return spreadings, nil
+}
+
+// domain returns the topology domain of the node. Nodes without the topology label have no domain.
+func (s *appSpreading) domain(node *api.Node) (string, bool) {
+	if node == nil {
+		return "", false
+	}
+	domain, exists := node.Labels[s.topologyKey]
+	return domain, exists
+}
+
+//NewAppTopologySpreadPredicate creates a predicate limiting the copies of an app in each topology domain.
+//Nodes without the topology label are not limited
+func NewAppTopologySpreadPredicate(policies []AppSpreadingPolicy, podLister algorithm.PodLister, nodeInfo pluginPred.NodeInfo) (algorithm.FitPredicate, error) {
+	spreadings, err := newAppSpreadings(policies)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(pod *api.Pod, meta interface{}, cacheInfo *schedulercache.NodeInfo) (bool, []algorithm.PredicateFailureReason, error) {
+		reasons := []algorithm.PredicateFailureReason{}
+		for _, spreading := range spreadings {
+			if spreading.maxPerDomain == 0 {
+				continue
+			}
+			identity, exists := spreading.identify(pod)
+			if !exists {
+				continue
+			}
+			domain, exists := spreading.domain(cacheInfo.Node())
+			if !exists {
+				continue
+			}
+
+			pods, err := podLister.List(identity.selector)
+			if err != nil {
+				return false, nil, err
+			}
+
+			count := 0
+			for _, p := range pods {
+				if p.Spec.NodeName == "" || p.Namespace != identity.namespace {
+					continue
+				}
+				node, err := nodeInfo.GetNodeInfo(p.Spec.NodeName)
+				if err != nil {
+					glog.V(4).Infof("Unable to find Node %s of Pod %s: %v", p.Spec.NodeName, p.Name, err)
+					continue
+				}
+				if podDomain, exists := spreading.domain(node); exists && podDomain == domain {
+					count++
+				}
+			}
+
+			if count >= spreading.maxPerDomain {
+				glog.V(10).Infof("Cannot schedule Pod %s, Because %s %s already has %d copies", pod.Name, spreading.topologyKey, domain, count)
+				reasons = append(reasons, spreading.failure)
+			}
+		}
+		return len(reasons) == 0, reasons, nil
+	}, nil
+}
+
+//NewAppTopologySpreadPriority creates a priority preferring the topology domains with the fewest copies of an app.
+//Every node in a domain gets the same score, so packing priorities like MostUsed still choose the node within the domain
+func NewAppTopologySpreadPriority(policies []AppSpreadingPolicy) (algorithm.PriorityFunction, error) {
+	spreadings, err := newAppSpreadings(policies)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(pod *api.Pod, nodeNameToInfo map[string]*schedulercache.NodeInfo, nodes []*api.Node) (schedulerapi.HostPriorityList, error) {
+		scores := make(map[string]int, len(nodes))
+		applied := 0
+		for _, spreading := range spreadings {
+			identity, exists := spreading.identify(pod)
+			if !exists {
+				continue
+			}
+			applied++
+
+			counts := countPodsByDomain(spreading, identity, nodeNameToInfo)
+			highest := 0
+			for _, count := range counts {
+				if count > highest {
+					highest = count
+				}
+			}
+
+			for _, node := range nodes {
+				domain, exists := spreading.domain(node)
+				if !exists {
+					continue
+				}
+				if highest == 0 {
+					scores[node.Name] += frameworkMaxPriority
+					continue
+				}
+				scores[node.Name] += frameworkMaxPriority * (highest - counts[domain]) / highest
+			}
+		}
+
+		list := schedulerapi.HostPriorityList{}
+		for _, node := range nodes {
+			score := frameworkMaxPriority
+			if applied > 0 {
+				score = scores[node.Name] / applied
+			}
+			list = append(list, schedulerapi.HostPriority{Host: node.Name, Score: score})
+		}
+		return list, nil
+	}, nil
+}
+
+// countPodsByDomain counts the pods matching 'identity' in each topology domain
+func countPodsByDomain(spreading *appSpreading, identity *app, nodeNameToInfo map[string]*schedulercache.NodeInfo) map[string]int {
+	counts := map[string]int{}
+	for _, info := range nodeNameToInfo {
+		domain, exists := spreading.domain(info.Node())
+		if !exists {
+			continue
+		}
+
+		for _, p := range info.Pods() {
+			if identity.matches(p) {
+				counts[domain]++
+			}
+		}
+	}
+	return counts
+}
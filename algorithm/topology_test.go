@@ -0,0 +1,207 @@
+package algorithm
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/plugin/pkg/scheduler/algorithm"
+	schedulerapi "k8s.io/kubernetes/plugin/pkg/scheduler/api"
+	"k8s.io/kubernetes/plugin/pkg/scheduler/schedulercache"
+)
+
+const zoneKey = "failure-domain.beta.kubernetes.io/zone"
+
+func createZoneNode(name, zone string) *api.Node {
+	node := makeNode(name, 4000, 10000)
+	if zone != "" {
+		node.Labels = map[string]string{zoneKey: zone}
+	}
+	return node
+}
+
+func placePod(pod *api.Pod, node string) *api.Pod {
+	placed := *pod
+	placed.Spec.NodeName = node
+	return &placed
+}
+
+var zoneSpreading = AppSpreadingPolicy{
+	AppIdentity:  AppIdentity{Name: "web", Selector: "team=web", IdentityKeys: []string{"app"}},
+	TopologyKey:  zoneKey,
+	MaxPerDomain: 2,
+}
+
+func TestAppTopologySpreadPredicate(t *testing.T) {
+	nodes := []*api.Node{
+		createZoneNode("a1", "a"),
+		createZoneNode("a2", "a"),
+		createZoneNode("b1", "b"),
+		createZoneNode("none", ""),
+	}
+	site := createAppPod("site", "v1", "web")
+
+	tests := []struct {
+		testName string
+		pod      *api.Pod
+		pods     []*api.Pod
+		node     *api.Node
+		expected bool
+	}{
+		{
+			testName: "EmptyZone",
+			pod:      site,
+			pods:     []*api.Pod{placePod(site, "a1"), placePod(site, "a2")},
+			node:     nodes[2],
+			expected: true,
+		},
+		{
+			testName: "FullZoneOtherNode",
+			pod:      site,
+			pods:     []*api.Pod{placePod(site, "a1"), placePod(site, "a1")},
+			node:     nodes[1],
+			expected: false,
+		},
+		{
+			testName: "ZoneBelowMax",
+			pod:      site,
+			pods:     []*api.Pod{placePod(site, "a1"), placePod(site, "b1")},
+			node:     nodes[1],
+			expected: true,
+		},
+		{
+			testName: "OtherAppsIgnored",
+			pod:      site,
+			pods:     []*api.Pod{placePod(createAppPod("api", "v1", "web"), "a1"), placePod(createAppPod("api", "v1", "web"), "a2")},
+			node:     nodes[0],
+			expected: true,
+		},
+		{
+			testName: "OtherNamespaceIgnored",
+			pod:      site,
+			pods:     []*api.Pod{placePod(inNamespace(site, "staging"), "a1"), placePod(inNamespace(site, "staging"), "a2")},
+			node:     nodes[0],
+			expected: true,
+		},
+		{
+			testName: "NodeWithoutZone",
+			pod:      site,
+			pods:     []*api.Pod{placePod(site, "none"), placePod(site, "none")},
+			node:     nodes[3],
+			expected: true,
+		},
+		{
+			testName: "NotSelected",
+			pod:      createAppPod("site", "v1", "backend"),
+			pods:     []*api.Pod{placePod(createAppPod("site", "v1", "backend"), "a1"), placePod(createAppPod("site", "v1", "backend"), "a2")},
+			node:     nodes[0],
+			expected: true,
+		},
+	}
+
+	for _, test := range tests {
+		predicate, err := NewAppTopologySpreadPredicate([]AppSpreadingPolicy{zoneSpreading}, algorithm.FakePodLister(test.pods), newTestNodeInfo(nodes))
+		if err != nil {
+			t.Fatalf("Unexpected error creating the predicate: %v", err)
+		}
+
+		info := schedulercache.NewNodeInfo()
+		info.SetNode(test.node)
+		actual, reasons, err := predicate(test.pod, nil, info)
+		if err != nil {
+			t.Errorf("Test %s had error %v", test.testName, err)
+		}
+
+		if actual != test.expected {
+			t.Errorf("Test %s. Expected: %v Actual: %v", test.testName, test.expected, actual)
+		}
+		if !test.expected && !hasReason(reasons, "AppTopologySpread-web") {
+			t.Errorf("Test %s. Expected reason AppTopologySpread-web in %v", test.testName, reasons)
+		}
+	}
+}
+
+func TestAppTopologySpreadPriority(t *testing.T) {
+	nodes := []*api.Node{
+		createZoneNode("a1", "a"),
+		createZoneNode("a2", "a"),
+		createZoneNode("b1", "b"),
+		createZoneNode("c1", "c"),
+		createZoneNode("none", ""),
+	}
+	site := createAppPod("site", "v1", "web")
+
+	tests := []struct {
+		testName     string
+		pod          *api.Pod
+		pods         []*api.Pod
+		expectedList schedulerapi.HostPriorityList
+	}{
+		{
+			/*
+				Zone a: 2 copies, Score: 10 * (2 - 2) / 2 = 0
+				Zone b: 1 copy, Score: 10 * (2 - 1) / 2 = 5
+				Zone c: 0 copies, Score: 10 * (2 - 0) / 2 = 10
+			*/
+			testName: "SpreadAcrossZones",
+			pod:      site,
+			pods:     []*api.Pod{placePod(site, "a1"), placePod(site, "a2"), placePod(site, "b1")},
+			expectedList: []schedulerapi.HostPriority{
+				{Host: "a1", Score: 0},
+				{Host: "a2", Score: 0},
+				{Host: "b1", Score: 5},
+				{Host: "c1", Score: 10},
+				{Host: "none", Score: 0},
+			},
+		},
+		{
+			testName: "NoCopies",
+			pod:      site,
+			expectedList: []schedulerapi.HostPriority{
+				{Host: "a1", Score: 10},
+				{Host: "a2", Score: 10},
+				{Host: "b1", Score: 10},
+				{Host: "c1", Score: 10},
+				{Host: "none", Score: 0},
+			},
+		},
+		{
+			testName: "OtherNamespaceIgnored",
+			pod:      site,
+			pods:     []*api.Pod{placePod(inNamespace(site, "staging"), "a1")},
+			expectedList: []schedulerapi.HostPriority{
+				{Host: "a1", Score: 10},
+				{Host: "a2", Score: 10},
+				{Host: "b1", Score: 10},
+				{Host: "c1", Score: 10},
+				{Host: "none", Score: 0},
+			},
+		},
+		{
+			testName: "NotSelected",
+			pod:      createAppPod("site", "v1", "backend"),
+			pods:     []*api.Pod{placePod(site, "a1")},
+			expectedList: []schedulerapi.HostPriority{
+				{Host: "a1", Score: 10},
+				{Host: "a2", Score: 10},
+				{Host: "b1", Score: 10},
+				{Host: "c1", Score: 10},
+				{Host: "none", Score: 10},
+			},
+		},
+	}
+
+	priority, err := NewAppTopologySpreadPriority([]AppSpreadingPolicy{zoneSpreading})
+	if err != nil {
+		t.Fatalf("Unexpected error creating the priority: %v", err)
+	}
+	for _, test := range tests {
+		list, err := priority(test.pod, schedulercache.CreateNodeNameToInfoMap(test.pods, nodes), nodes)
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if !reflect.DeepEqual(test.expectedList, list) {
+			t.Errorf("%s: expected %#v, got %#v", test.testName, test.expectedList, list)
+		}
+	}
+}
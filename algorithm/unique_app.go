@@ -0,0 +1,168 @@
+package algorithm
+
+import (
+	"fmt"
+
+	"k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/labels"
+	"k8s.io/kubernetes/plugin/pkg/scheduler/algorithm"
+	pluginPred "k8s.io/kubernetes/plugin/pkg/scheduler/algorithm/predicates"
+	"k8s.io/kubernetes/plugin/pkg/scheduler/schedulercache"
+)
+
+var (
+	appIdentityPresets = map[string]AppIdentity{
+		"deis": {
+			Name:     "deis",
+			Selector: "heritage=deis",
+		},
+	}
+
+	deisUniqueApp = &uniqueApp{
+		appIdentity: &appIdentity{
+			name:     "deis",
+			selector: labels.SelectorFromSet(labels.Set{"heritage": "deis"}),
+		},
+		maxPerNode: 1,
+		failure:    deisUniqueAppPredError,
+	}
+)
+
+// appIdentity is an AppIdentity ready to match pods against
+type appIdentity struct {
+	name     string
+	selector labels.Selector
+	keys     []string
+}
+
+func newAppIdentity(identity AppIdentity) (*appIdentity, error) {
+	if identity.Preset != "" {
+		preset, exists := appIdentityPresets[identity.Preset]
+		if !exists {
+			return nil, fmt.Errorf("Unknown app identity preset: %s", identity.Preset)
+		}
+		if identity.Name == "" {
+			identity.Name = preset.Name
+		}
+		if identity.Selector == "" {
+			identity.Selector = preset.Selector
+		}
+		if len(identity.IdentityKeys) == 0 {
+			identity.IdentityKeys = preset.IdentityKeys
+		}
+	}
+
+	if identity.Name == "" {
+		return nil, fmt.Errorf("App identity needs a name")
+	}
+
+	selector, err := labels.Parse(identity.Selector)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid selector for app identity %s: %v", identity.Name, err)
+	}
+
+	return &appIdentity{
+		name:     identity.Name,
+		selector: selector,
+		keys:     identity.IdentityKeys,
+	}, nil
+}
+
+// app matches the pods of a single app. Like the spreading of the default scheduler, an app
+// never spans namespaces
+type app struct {
+	namespace string
+	selector  labels.Selector
+}
+
+func (a *app) matches(pod *api.Pod) bool {
+	return pod.Namespace == a.namespace && a.selector.Matches(labels.Set(pod.Labels))
+}
+
+// identify returns the app of 'pod', matching the pods of the same app in its namespace. Pods
+// the identity does not apply to have no app.
+func (a *appIdentity) identify(pod *api.Pod) (*app, bool) {
+	if !a.selector.Matches(labels.Set(pod.Labels)) {
+		return nil, false
+	}
+
+	if len(a.keys) == 0 {
+		return &app{namespace: pod.Namespace, selector: labels.SelectorFromSet(pod.Labels)}, true
+	}
+
+	identity := labels.Set{}
+	for _, key := range a.keys {
+		value, exists := pod.Labels[key]
+		if !exists {
+			return nil, false
+		}
+		identity[key] = value
+	}
+	return &app{namespace: pod.Namespace, selector: labels.SelectorFromSet(identity)}, true
+}
+
+// uniqueApp is a UniqueAppPolicy ready to match pods against
+type uniqueApp struct {
+	*appIdentity
+	maxPerNode int
+	failure    *pluginPred.PredicateFailureError
+}
+
+func newUniqueApp(policy UniqueAppPolicy) (*uniqueApp, error) {
+	identity, err := newAppIdentity(policy.AppIdentity)
+	if err != nil {
+		return nil, err
+	}
+
+	if policy.MaxPerNode == 0 {
+		policy.MaxPerNode = 1
+	}
+	if policy.MaxPerNode < 1 {
+		return nil, fmt.Errorf("Unique app policy %s must allow at least one pod per node: %d", identity.name, policy.MaxPerNode)
+	}
+
+	return &uniqueApp{
+		appIdentity: identity,
+		maxPerNode:  policy.MaxPerNode,
+		failure:     newPredicateFailure(fmt.Sprintf("%s-%s", uniqueAppPred, identity.name)),
+	}, nil
+}
+
+// fits checks that adding 'pod' to 'pods' stays within the copies of the app allowed on a node.
+// Only the copies in the namespace of 'pod' count
+func (a *uniqueApp) fits(pod *api.Pod, pods []*api.Pod) bool {
+	identity, exists := a.identify(pod)
+	if !exists {
+		return true
+	}
+
+	count := 0
+	for _, p := range pods {
+		if identity.matches(p) {
+			count++
+		}
+	}
+	return count < a.maxPerNode
+}
+
+//NewUniqueAppPredicate creates a predicate limiting the copies of an app on each node for every given policy
+func NewUniqueAppPredicate(policies []UniqueAppPolicy) (algorithm.FitPredicate, error) {
+	apps := []*uniqueApp{}
+	for _, policy := range policies {
+		app, err := newUniqueApp(policy)
+		if err != nil {
+			return nil, err
+		}
+		apps = append(apps, app)
+	}
+
+	return func(pod *api.Pod, meta interface{}, cacheInfo *schedulercache.NodeInfo) (bool, []algorithm.PredicateFailureReason, error) {
+		reasons := []algorithm.PredicateFailureReason{}
+		for _, app := range apps {
+			if !app.fits(pod, cacheInfo.Pods()) {
+				reasons = append(reasons, app.failure)
+			}
+		}
+		return len(reasons) == 0, reasons, nil
+	}, nil
+}
@@ -0,0 +1,161 @@
+package algorithm
+
+import (
+	"testing"
+
+	"k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/plugin/pkg/scheduler/schedulercache"
+)
+
+func createAppPod(app, version, team string) *api.Pod {
+	return &api.Pod{
+		ObjectMeta: api.ObjectMeta{
+			Labels: map[string]string{
+				"app":     app,
+				"version": version,
+				"team":    team,
+			},
+		},
+	}
+}
+
+func inNamespace(pod *api.Pod, namespace string) *api.Pod {
+	moved := *pod
+	moved.Namespace = namespace
+	return &moved
+}
+
+func TestUniqueAppPredicate(t *testing.T) {
+	tests := []struct {
+		testName string
+		policy   UniqueAppPolicy
+		pod      *api.Pod
+		existing []*api.Pod
+		expected bool
+		reason   string
+	}{
+		{
+			testName: "NotSelected",
+			policy:   UniqueAppPolicy{AppIdentity: AppIdentity{Name: "web", Selector: "team=web", IdentityKeys: []string{"app"}}, MaxPerNode: 1},
+			pod:      createAppPod("api", "v1", "backend"),
+			existing: []*api.Pod{createAppPod("api", "v1", "backend")},
+			expected: true,
+		},
+		{
+			testName: "SameIdentity",
+			policy:   UniqueAppPolicy{AppIdentity: AppIdentity{Name: "web", Selector: "team=web", IdentityKeys: []string{"app"}}, MaxPerNode: 1},
+			pod:      createAppPod("site", "v2", "web"),
+			existing: []*api.Pod{createAppPod("site", "v1", "web")},
+			expected: false,
+			reason:   "UniqueApp-web",
+		},
+		{
+			testName: "OtherNamespace",
+			policy:   UniqueAppPolicy{AppIdentity: AppIdentity{Name: "web", Selector: "team=web", IdentityKeys: []string{"app"}}, MaxPerNode: 1},
+			pod:      createAppPod("site", "v1", "web"),
+			existing: []*api.Pod{inNamespace(createAppPod("site", "v1", "web"), "staging")},
+			expected: true,
+		},
+		{
+			testName: "DifferentIdentity",
+			policy:   UniqueAppPolicy{AppIdentity: AppIdentity{Name: "web", Selector: "team=web", IdentityKeys: []string{"app", "version"}}, MaxPerNode: 1},
+			pod:      createAppPod("site", "v2", "web"),
+			existing: []*api.Pod{createAppPod("site", "v1", "web")},
+			expected: true,
+		},
+		{
+			testName: "MissingIdentityKey",
+			policy:   UniqueAppPolicy{AppIdentity: AppIdentity{Name: "web", Selector: "team=web", IdentityKeys: []string{"tier"}}, MaxPerNode: 1},
+			pod:      createAppPod("site", "v1", "web"),
+			existing: []*api.Pod{createAppPod("site", "v1", "web")},
+			expected: true,
+		},
+		{
+			testName: "UnderMaxPerNode",
+			policy:   UniqueAppPolicy{AppIdentity: AppIdentity{Name: "web", Selector: "team=web", IdentityKeys: []string{"app"}}, MaxPerNode: 3},
+			pod:      createAppPod("site", "v1", "web"),
+			existing: []*api.Pod{createAppPod("site", "v1", "web"), createAppPod("site", "v1", "web")},
+			expected: true,
+		},
+		{
+			testName: "AtMaxPerNode",
+			policy:   UniqueAppPolicy{AppIdentity: AppIdentity{Name: "web", Selector: "team=web", IdentityKeys: []string{"app"}}, MaxPerNode: 2},
+			pod:      createAppPod("site", "v1", "web"),
+			existing: []*api.Pod{createAppPod("site", "v1", "web"), createAppPod("site", "v1", "web")},
+			expected: false,
+			reason:   "UniqueApp-web",
+		},
+		{
+			testName: "DeisPreset",
+			policy:   UniqueAppPolicy{AppIdentity: AppIdentity{Preset: "deis"}},
+			pod:      createDeisPod("v1"),
+			existing: []*api.Pod{createDeisPod("v1")},
+			expected: false,
+			reason:   "UniqueApp-deis",
+		},
+		{
+			testName: "DeisPresetDifferentVersion",
+			policy:   UniqueAppPolicy{AppIdentity: AppIdentity{Preset: "deis"}},
+			pod:      createDeisPod("v2"),
+			existing: []*api.Pod{createDeisPod("v1")},
+			expected: true,
+		},
+		{
+			testName: "DeisPresetMaxPerNode",
+			policy:   UniqueAppPolicy{AppIdentity: AppIdentity{Preset: "deis"}, MaxPerNode: 2},
+			pod:      createDeisPod("v1"),
+			existing: []*api.Pod{createDeisPod("v1")},
+			expected: true,
+		},
+	}
+
+	for _, test := range tests {
+		predicate, err := NewUniqueAppPredicate([]UniqueAppPolicy{test.policy})
+		if err != nil {
+			t.Errorf("Test %s had error creating the predicate %v", test.testName, err)
+			continue
+		}
+
+		actual, reasons, err := predicate(test.pod, nil, schedulercache.NewNodeInfo(test.existing...))
+		if err != nil {
+			t.Errorf("Test %s had error %v", test.testName, err)
+		}
+
+		if actual != test.expected {
+			t.Errorf("Test %s. Expected: %v Actual: %v", test.testName, test.expected, actual)
+		}
+		if !test.expected && !hasReason(reasons, test.reason) {
+			t.Errorf("Test %s. Expected reason %s in %v", test.testName, test.reason, reasons)
+		}
+	}
+}
+
+func TestNewUniqueAppPredicateInvalid(t *testing.T) {
+	tests := []struct {
+		testName string
+		policy   UniqueAppPolicy
+	}{
+		{
+			testName: "NoName",
+			policy:   UniqueAppPolicy{AppIdentity: AppIdentity{Selector: "team=web"}, MaxPerNode: 1},
+		},
+		{
+			testName: "NegativeMaxPerNode",
+			policy:   UniqueAppPolicy{AppIdentity: AppIdentity{Name: "web", Selector: "team=web"}, MaxPerNode: -1},
+		},
+		{
+			testName: "UnknownPreset",
+			policy:   UniqueAppPolicy{AppIdentity: AppIdentity{Preset: "heroku"}},
+		},
+		{
+			testName: "InvalidSelector",
+			policy:   UniqueAppPolicy{AppIdentity: AppIdentity{Name: "web", Selector: "team in (web"}, MaxPerNode: 1},
+		},
+	}
+
+	for _, test := range tests {
+		if _, err := NewUniqueAppPredicate([]UniqueAppPolicy{test.policy}); err == nil {
+			t.Errorf("Test %s expected an error", test.testName)
+		}
+	}
+}
@@ -1,7 +1,7 @@
 package algorithm
 
 import (
-	"math"
+	"sort"
 
 	"github.com/golang/glog"
 	"k8s.io/kubernetes/pkg/api"
@@ -9,8 +9,8 @@ import (
 )
 
 // For each of these resources, a pod that doesn't request the resource explicitly
-// will be treated as having requested the amount indicated below, for the purpose
-// of computing priority only. This ensures that when scheduling zero-request pods, such
+// will be treated as having requested the amount indicated below, unless the packing
+// policy sets other defaults. This ensures that when scheduling zero-request pods, such
 // pods will not all be scheduled to the machine with the smallest in-use request,
 // and that when scheduling regular pods, such pods will not see zero-request pods as
 // consuming no resources whatsoever. We chose these values to be similar to the
@@ -19,42 +19,181 @@ import (
 const defaultMilliCPURequest int64 = 250             // 0.25 core
 const defaultMemoryRequest int64 = 500 * 1024 * 1024 // 500 MB
 
-// TODO: Consider setting default as a fixed fraction of machine capacity (take "capacity api.ResourceList"
-// as an additional argument here) rather than using constants
-func getNonzeroRequests(requests *api.ResourceList) (int64, int64) {
-	var millicpu, memory int64
-	// Override if un-set, but not if explicitly set to zero
-	if (*requests.Cpu() == resource.Quantity{}) {
-		millicpu = defaultMilliCPURequest
-	} else {
-		millicpu = requests.Cpu().MilliValue()
+// Annotation with the resources a pod uses outside of its containers, e.g. cpu=100m,memory=64Mi
+const podOverheadAnnotation = "packscheduler.alpha.kubernetes.io/pod-overhead"
+
+// resourceList is the amount of each resource used for packing. CPU is tracked in
+// millicores, every other resource uses its plain value.
+type resourceList map[api.ResourceName]int64
+
+func (r resourceList) add(other resourceList) {
+	for name, value := range other {
+		r[name] += value
+	}
+}
+
+// max raises each resource in the list to at least the amount in 'other'
+func (r resourceList) max(other resourceList) {
+	for name, value := range other {
+		if value > r[name] {
+			r[name] = value
+		}
+	}
+}
+
+// names returns the resources used in the list, sorted so CPU and memory always come first
+func (r resourceList) names() []api.ResourceName {
+	names := []api.ResourceName{api.ResourceCPU, api.ResourceMemory}
+	others := []string{}
+	for name, value := range r {
+		if name != api.ResourceCPU && name != api.ResourceMemory && value > 0 {
+			others = append(others, string(name))
+		}
+	}
+	sort.Strings(others)
+	for _, name := range others {
+		names = append(names, api.ResourceName(name))
+	}
+	return names
+}
+
+func quantityValue(name api.ResourceName, quantity *resource.Quantity) int64 {
+	if name == api.ResourceCPU {
+		return quantity.MilliValue()
+	}
+	return quantity.Value()
+}
+
+// getCapacity returns the amount of a resource the node can give to pods. When the packing
+// policy uses allocatable resources, Allocatable is used if the node reports it for the
+// resource, otherwise Capacity is used.
+func getCapacity(name api.ResourceName, node *api.Node) int64 {
+	if packingPolicy.UseAllocatable {
+		if quantity, exists := node.Status.Allocatable[name]; exists {
+			return quantityValue(name, &quantity)
+		}
+	}
+
+	quantity, exists := node.Status.Capacity[name]
+	if !exists {
+		return 0
+	}
+	return quantityValue(name, &quantity)
+}
+
+func getResourceValues(resources api.ResourceList) resourceList {
+	result := resourceList{}
+	for name, quantity := range resources {
+		result[name] = quantityValue(name, &quantity)
 	}
+	return result
+}
+
+// getDefaultRequests returns the amount of each resource a container that neither requests
+// nor limits the resource is treated as using. Namespace overrides of the packing policy take
+// precedence, and defaults set as a fraction of capacity are relative to 'node'.
+func getDefaultRequests(namespace string, node *api.Node) resourceList {
+	defaults := resourceList{}
+	packingPolicy.DefaultRequests.apply(defaults, node)
+	if override, exists := packingPolicy.NamespaceDefaultRequests[namespace]; exists {
+		override.apply(defaults, node)
+	}
+	return defaults
+}
+
+func (d ResourceDefaults) apply(defaults resourceList, node *api.Node) {
+	for name, quantity := range d.Resources {
+		defaults[name] = quantityValue(name, &quantity)
+	}
+	if node == nil {
+		return
+	}
+	for name, fraction := range d.CapacityFractions {
+		defaults[name] = int64(float64(getCapacity(name, node)) * fraction)
+	}
+}
+
+func getResourcesForPacking(resources *api.ResourceRequirements, defaults resourceList) resourceList {
+	requests := getResourceValues(resources.Requests)
+	limits := getResourceValues(resources.Limits)
 	// Override if un-set, but not if explicitly set to zero
-	if (*requests.Memory() == resource.Quantity{}) {
-		memory = defaultMemoryRequest
-	} else {
-		memory = requests.Memory().Value()
+	for name, value := range defaults {
+		_, requested := resources.Requests[name]
+		_, limited := resources.Limits[name]
+		if !requested && !limited {
+			requests[name] = value
+		}
+		// The max mode never packs a container below the default, so a small request without a limit still counts as the default
+		if !limited && packingPolicy.ResourceMode == ResourceModeMax {
+			limits[name] = value
+		}
 	}
-	return millicpu, memory
+
+	glog.V(10).Infof("Requests: %v  Limits: %v", requests, limits)
+
+	result := resourceList{}
+	for name := range requests {
+		result[name] = combineResources(requests[name], limits[name], packingPolicy.ResourceMode, packingPolicy.LimitWeight)
+	}
+	for name := range limits {
+		result[name] = combineResources(requests[name], limits[name], packingPolicy.ResourceMode, packingPolicy.LimitWeight)
+	}
+	return result
 }
 
-func getResourcesForPacking(resources *api.ResourceRequirements) (int64, int64) {
-	rc, rm := getNonzeroRequests(&resources.Requests)
-	lc, lm := getNonzeroRequests(&resources.Limits)
+// combineResources sizes a single resource of a container from its request and limit
+func combineResources(request, limit int64, mode ResourceMode, limitWeight float64) int64 {
+	switch mode {
+	case ResourceModeRequests:
+		return request
+	case ResourceModeLimits:
+		if limit == 0 {
+			return request
+		}
+		return limit
+	case ResourceModeInterpolate:
+		if limit < request {
+			limit = request
+		}
+		return request + int64(float64(limit-request)*limitWeight)
+	}
+
+	if limit > request {
+		return limit
+	}
+	return request
+}
 
-	glog.V(10).Infof("Requests: (%d, %d)  Limits: (%d, %d)", rc, rm, lc, lm)
+// getPodOverhead returns the resources the pod uses outside of its containers, as set
+// by the pod overhead annotation
+func getPodOverhead(pod *api.Pod) resourceList {
+	value, exists := pod.Annotations[podOverheadAnnotation]
+	if !exists {
+		return resourceList{}
+	}
 
-	return int64(math.Max(float64(rc), float64(lc))), int64(math.Max(float64(rm), float64(lm)))
+	overhead, err := parseResourceList(value)
+	if err != nil {
+		glog.Warningf("Ignoring invalid %s annotation on Pod %s: %v", podOverheadAnnotation, pod.Name, err)
+		return resourceList{}
+	}
+	return getResourceValues(overhead)
 }
 
-func getResourcesForPod(pod *api.Pod) (int64, int64) {
-	totalCPU := int64(0)
-	totalMemory := int64(0)
+// getResourcesForPod returns the size of the pod when packed on 'node'. Like the kubelet, the
+// pod is sized by the larger of its biggest init container and the sum of its app containers,
+// plus any pod overhead.
+func getResourcesForPod(pod *api.Pod, node *api.Node) resourceList {
+	defaults := getDefaultRequests(pod.Namespace, node)
+	total := resourceList{}
 	for _, container := range pod.Spec.Containers {
-		cpu, memory := getResourcesForPacking(&container.Resources)
-		totalCPU += cpu
-		totalMemory += memory
+		total.add(getResourcesForPacking(&container.Resources, defaults))
+	}
+	// Init containers run one at a time before the app containers start
+	for _, container := range pod.Spec.InitContainers {
+		total.max(getResourcesForPacking(&container.Resources, defaults))
 	}
+	total.add(getPodOverhead(pod))
 
-	return totalCPU, totalMemory
+	return total
 }
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/golang/glog"
+	"github.com/jmccarty3/packScheduler/algorithm"
+
+	"k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/api/unversioned"
+	"k8s.io/kubernetes/pkg/client/cache"
+	"k8s.io/kubernetes/pkg/client/clientset_generated/internalclientset"
+	"k8s.io/kubernetes/pkg/client/restclient"
+	unversionedclient "k8s.io/kubernetes/pkg/client/unversioned"
+	"k8s.io/kubernetes/pkg/client/unversioned/clientcmd"
+	"k8s.io/kubernetes/pkg/controller/framework"
+	"k8s.io/kubernetes/pkg/runtime"
+	"k8s.io/kubernetes/pkg/util/wait"
+	"k8s.io/kubernetes/pkg/watch"
+	"k8s.io/kubernetes/plugin/cmd/kube-scheduler/app/options"
+	"k8s.io/kubernetes/plugin/pkg/scheduler/factory"
+)
+
+// newClientConfig builds the config for the API server the scheduler talks to
+func newClientConfig(s *options.SchedulerServer) (*restclient.Config, error) {
+	config, err := clientcmd.BuildConfigFromFlags(s.Master, s.Kubeconfig)
+	if err != nil {
+		return nil, err
+	}
+	config.ContentType = s.ContentType
+	config.QPS = s.KubeAPIQPS
+	config.Burst = int(s.KubeAPIBurst)
+	return config, nil
+}
+
+// newClient creates a client for the API server the scheduler talks to
+func newClient(s *options.SchedulerServer) (*internalclientset.Clientset, error) {
+	config, err := newClientConfig(s)
+	if err != nil {
+		return nil, err
+	}
+	return internalclientset.NewForConfig(config)
+}
+
+// newLegacyClient creates a client for the APIs the generated clientset does not cover yet, like
+// PodDisruptionBudgets and evictions
+func newLegacyClient(s *options.SchedulerServer) (*unversionedclient.Client, error) {
+	config, err := newClientConfig(s)
+	if err != nil {
+		return nil, err
+	}
+	return unversionedclient.New(config)
+}
+
+// clusterLister lists the nodes and pods of the cluster from the scheduler cache, or from informer caches
+// until the scheduler has built the packing predicates and priorities
+type clusterLister struct {
+	client internalclientset.Interface
+	nodes  cache.Store
+	pods   cache.Store
+}
+
+// newClusterLister starts watching the nodes and pods of the cluster. Pods bound by 'schedulerName' have the
+// score of the node they were bound to recorded
+func newClusterLister(client internalclientset.Interface, schedulerName string) *clusterLister {
+	c := &clusterLister{client: client}
+
+	var nodeController, podController *framework.Controller
+	c.nodes, nodeController = framework.NewInformer(
+		&cache.ListWatch{
+			ListFunc: func(options api.ListOptions) (runtime.Object, error) {
+				return client.Core().Nodes().List(options)
+			},
+			WatchFunc: func(options api.ListOptions) (watch.Interface, error) {
+				return client.Core().Nodes().Watch(options)
+			},
+		},
+		&api.Node{},
+		0,
+		framework.ResourceEventHandlerFuncs{},
+	)
+	c.pods, podController = framework.NewInformer(
+		&cache.ListWatch{
+			ListFunc: func(options api.ListOptions) (runtime.Object, error) {
+				return client.Core().Pods(api.NamespaceAll).List(options)
+			},
+			WatchFunc: func(options api.ListOptions) (watch.Interface, error) {
+				return client.Core().Pods(api.NamespaceAll).Watch(options)
+			},
+		},
+		&api.Pod{},
+		0,
+		framework.ResourceEventHandlerFuncs{
+			UpdateFunc: func(oldObj, newObj interface{}) {
+				old, pod := oldObj.(*api.Pod), newObj.(*api.Pod)
+				if old.Spec.NodeName == "" && pod.Spec.NodeName != "" && podSchedulerName(pod) == schedulerName {
+					c.observePlacement(pod)
+				}
+			},
+		},
+	)
+
+	go nodeController.Run(wait.NeverStop)
+	go podController.Run(wait.NeverStop)
+	return c
+}
+
+// podSchedulerName returns the name of the scheduler the pod asks for
+func podSchedulerName(pod *api.Pod) string {
+	if name, exists := pod.Annotations[factory.SchedulerAnnotationKey]; exists {
+		return name
+	}
+	return api.DefaultSchedulerName
+}
+
+// observePlacement records the score of the node a pod was just bound to, next to the other pods on it
+func (c *clusterLister) observePlacement(pod *api.Pod) {
+	obj, exists, err := c.nodes.GetByKey(pod.Spec.NodeName)
+	if err != nil || !exists {
+		glog.V(4).Infof("Unable to find node %s of Pod %s/%s: %v", pod.Spec.NodeName, pod.Namespace, pod.Name, err)
+		return
+	}
+
+	pods := []*api.Pod{}
+	for _, p := range c.assignedPods() {
+		if p.Spec.NodeName == pod.Spec.NodeName && p.UID != pod.UID {
+			pods = append(pods, p)
+		}
+	}
+	algorithm.ObservePlacement(pod, obj.(*api.Node), pods)
+}
+
+// assignedPods returns the pods of the informer cache that are bound to a node and not terminated
+func (c *clusterLister) assignedPods() []*api.Pod {
+	pods := []*api.Pod{}
+	for _, obj := range c.pods.List() {
+		pod := obj.(*api.Pod)
+		if pod.Spec.NodeName == "" || pod.Status.Phase == api.PodSucceeded || pod.Status.Phase == api.PodFailed {
+			continue
+		}
+		pods = append(pods, pod)
+	}
+	return pods
+}
+
+// list returns the schedulable nodes and the pods running on them. Pods the scheduler assumed
+// but that are not bound yet are only included once the scheduler cache is available
+func (c *clusterLister) list() ([]*api.Node, []*api.Pod, error) {
+	if nodes, pods, ok, err := algorithm.ListSchedulerCache(); ok {
+		if err != nil {
+			return nil, nil, fmt.Errorf("Unable to list the scheduler cache: %v", err)
+		}
+		return nodes, pods, nil
+	}
+
+	nodes := []*api.Node{}
+	for _, obj := range c.nodes.List() {
+		if node := obj.(*api.Node); !node.Spec.Unschedulable {
+			nodes = append(nodes, node)
+		}
+	}
+	return nodes, c.assignedPods(), nil
+}
+
+// listDisruptionBudgets returns how many more pods each PodDisruptionBudget allows to be evicted
+func listDisruptionBudgets(client *unversionedclient.Client) ([]algorithm.DisruptionBudget, error) {
+	list, err := client.Policy().PodDisruptionBudgets(api.NamespaceAll).List(api.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("Unable to list disruption budgets: %v", err)
+	}
+
+	budgets := []algorithm.DisruptionBudget{}
+	for _, pdb := range list.Items {
+		selector, err := unversioned.LabelSelectorAsSelector(pdb.Spec.Selector)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid selector of disruption budget %s/%s: %v", pdb.Namespace, pdb.Name, err)
+		}
+
+		allowed := 0
+		if pdb.Status.PodDisruptionAllowed {
+			allowed = int(pdb.Status.CurrentHealthy - pdb.Status.DesiredHealthy)
+		}
+		budgets = append(budgets, algorithm.DisruptionBudget{
+			Namespace: pdb.Namespace,
+			Name:      pdb.Name,
+			Selector:  selector,
+			Allowed:   allowed,
+		})
+	}
+	return budgets, nil
+}
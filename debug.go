@@ -0,0 +1,133 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/golang/glog"
+	"github.com/jmccarty3/packScheduler/algorithm"
+	"github.com/spf13/pflag"
+
+	"k8s.io/kubernetes/pkg/api"
+)
+
+const (
+	debugScorePath    = "/debug/packing/score"
+	debugSnapshotPath = "/debug/packing/snapshot"
+	debugPreemptPath  = "/debug/packing/preemption"
+)
+
+var debugAddress string
+
+func addDebugFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&debugAddress, "packing-debug-address", debugAddress, "Address serving healthz, the "+debugScorePath+" endpoint, which scores a pod spec POSTed to it against the cluster without binding it, and the "+debugSnapshotPath+" endpoint, which returns a snapshot of the scheduler cache for the simulator, and the "+debugPreemptPath+" endpoint, which lists the lower priority pods to evict for a pod spec POSTed to it to fit, e.g. 127.0.0.1:10260. Disabled when empty")
+}
+
+// startDebugServer serves the debug endpoints and the healthz registered on the default mux
+func startDebugServer(cluster *clusterLister) {
+	if debugAddress == "" {
+		return
+	}
+
+	http.Handle(debugScorePath, &scoreHandler{cluster: cluster})
+	http.Handle(debugSnapshotPath, &snapshotHandler{})
+	http.Handle(debugPreemptPath, &preemptionHandler{cluster: cluster})
+	go func() {
+		if err := http.ListenAndServe(debugAddress, nil); err != nil {
+			glog.Errorf("Debug server on %s stopped: %v", debugAddress, err)
+		}
+	}()
+}
+
+// scoreHandler evaluates the pod in the request body against the current nodes and pods of the cluster
+type scoreHandler struct {
+	cluster *clusterLister
+}
+
+func (h *scoreHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	pod, nodes, pods, ok := readPodRequest(w, r, h.cluster)
+	if !ok {
+		return
+	}
+
+	record, err := algorithm.EvaluatePod(pod, nodes, pods)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Unable to evaluate pod: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(record); err != nil {
+		glog.Warningf("Unable to write the evaluation of Pod %s/%s: %v", pod.Namespace, pod.Name, err)
+	}
+}
+
+// preemptionHandler lists the nodes the pod in the request body fits on by evicting lower priority pods
+type preemptionHandler struct {
+	cluster *clusterLister
+}
+
+func (h *preemptionHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	pod, nodes, pods, ok := readPodRequest(w, r, h.cluster)
+	if !ok {
+		return
+	}
+
+	candidates := algorithm.PreemptionCandidates(pod, nodes, pods)
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(candidates); err != nil {
+		glog.Warningf("Unable to write the preemption candidates of Pod %s/%s: %v", pod.Namespace, pod.Name, err)
+	}
+}
+
+// readPodRequest decodes the v1 pod POSTed in the request and lists the cluster to place it on. The error
+// response is written when it returns false
+func readPodRequest(w http.ResponseWriter, r *http.Request, cluster *clusterLister) (*api.Pod, []*api.Node, []*api.Pod, bool) {
+	if r.Method != "POST" {
+		http.Error(w, "POST a pod spec to evaluate it", http.StatusMethodNotAllowed)
+		return nil, nil, nil, false
+	}
+
+	data, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Unable to read the pod: %v", err), http.StatusBadRequest)
+		return nil, nil, nil, false
+	}
+	pod := &api.Pod{}
+	if err := decodeObject(data, "Pod", pod); err != nil {
+		http.Error(w, fmt.Sprintf("Invalid pod: %v", err), http.StatusBadRequest)
+		return nil, nil, nil, false
+	}
+	if pod.Namespace == "" {
+		pod.Namespace = api.NamespaceDefault
+	}
+
+	nodes, pods, err := cluster.list()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return nil, nil, nil, false
+	}
+	return pod, nodes, pods, true
+}
+
+// snapshotHandler returns a snapshot of the nodes and pods in the scheduler cache
+type snapshotHandler struct{}
+
+func (h *snapshotHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	snapshot, ok, err := algorithm.SnapshotSchedulerCache()
+	if !ok {
+		http.Error(w, "The scheduler cache is not available yet", http.StatusServiceUnavailable)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := algorithm.WriteSnapshot(w, snapshot); err != nil {
+		glog.Warningf("Unable to write the cluster snapshot: %v", err)
+	}
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/client/cache"
+)
+
+func TestReadPodRequest(t *testing.T) {
+	cluster := &clusterLister{
+		nodes: cache.NewStore(cache.MetaNamespaceKeyFunc),
+		pods:  cache.NewStore(cache.MetaNamespaceKeyFunc),
+	}
+
+	// The init containers of a v1 pod are only carried by their alpha annotation
+	body := `{
+  "apiVersion": "v1",
+  "kind": "Pod",
+  "metadata": {
+    "name": "web",
+    "annotations": {"pod.alpha.kubernetes.io/init-containers": "[{\"name\": \"migrate\"}]"}
+  },
+  "spec": {"containers": [{"name": "web"}]}
+}`
+	r, err := http.NewRequest("POST", debugScorePath, strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	w := httptest.NewRecorder()
+
+	pod, _, _, ok := readPodRequest(w, r, cluster)
+	if !ok {
+		t.Fatalf("Expected the pod to be read, got %d %s", w.Code, w.Body.String())
+	}
+	if pod.Namespace != api.NamespaceDefault {
+		t.Errorf("Expected the default namespace, got %q", pod.Namespace)
+	}
+	if len(pod.Spec.InitContainers) != 1 || pod.Spec.InitContainers[0].Name != "migrate" {
+		t.Errorf("Expected the init container migrate, got %+v", pod.Spec.InitContainers)
+	}
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/golang/glog"
+	"github.com/jmccarty3/packScheduler/algorithm"
+	"github.com/spf13/pflag"
+
+	"k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/client/clientset_generated/internalclientset"
+	unversionedcore "k8s.io/kubernetes/pkg/client/clientset_generated/internalclientset/typed/core/unversioned"
+	"k8s.io/kubernetes/pkg/client/record"
+)
+
+const (
+	explainPodEvent      = "event"
+	explainPodAnnotation = "annotation"
+
+	decisionEventReason  = "PackingDecision"
+	decisionAnnotation   = "packscheduler.alpha.kubernetes.io/decision"
+	explainComponentName = "packScheduler"
+)
+
+var (
+	explainLog  bool
+	explainFile string
+	explainPod  string
+)
+
+func addExplainFlags(fs *pflag.FlagSet) {
+	fs.BoolVar(&explainLog, "explain-decisions-log", explainLog, "Write the packing decision record of every pod to the scheduler log as JSON")
+	fs.StringVar(&explainFile, "explain-decisions-file", explainFile, "File to append the packing decision record of every pod to, one JSON record per line")
+	fs.StringVar(&explainPod, "explain-decisions-pod", explainPod, "Attach the packing decision record to the pod: event adds a summary event, annotation sets the "+decisionAnnotation+" annotation")
+}
+
+// setupExplain adds the decision sinks requested on the command line
+func setupExplain(client internalclientset.Interface) error {
+	if explainLog {
+		algorithm.AddDecisionSink(algorithm.NewLogDecisionSink())
+	}
+
+	if explainFile != "" {
+		file, err := os.OpenFile(explainFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			return fmt.Errorf("Unable to open decision file %s: %v", explainFile, err)
+		}
+		algorithm.AddDecisionSink(algorithm.NewJSONDecisionSink(file))
+	}
+
+	switch explainPod {
+	case "":
+		return nil
+	case explainPodEvent, explainPodAnnotation:
+	default:
+		return fmt.Errorf("Unknown pod decision output: %q", explainPod)
+	}
+
+	if explainPod == explainPodEvent {
+		broadcaster := record.NewBroadcaster()
+		broadcaster.StartRecordingToSink(&unversionedcore.EventSinkImpl{Interface: client.Core().Events("")})
+		algorithm.AddDecisionSink(&eventDecisionSink{
+			recorder: broadcaster.NewRecorder(api.EventSource{Component: explainComponentName}),
+		})
+		return nil
+	}
+
+	algorithm.AddDecisionSink(&annotationDecisionSink{client: client})
+	return nil
+}
+
+// eventDecisionSink adds an event summarizing the decision to the pod
+type eventDecisionSink struct {
+	recorder record.EventRecorder
+}
+
+func (s *eventDecisionSink) RecordDecision(decision *algorithm.DecisionRecord) error {
+	// Pods without a UID were never created, so there is nothing to attach the decision to
+	if decision.UID == "" {
+		return nil
+	}
+
+	ref := &api.ObjectReference{
+		Kind:      "Pod",
+		Namespace: decision.Namespace,
+		Name:      decision.Name,
+		UID:       decision.UID,
+	}
+	s.recorder.Event(ref, api.EventTypeNormal, decisionEventReason, decision.Summary())
+	return nil
+}
+
+// annotationDecisionSink sets the decision record as an annotation on the pod once it is bound. Updating
+// a pending pod would hand it back to the scheduler
+type annotationDecisionSink struct {
+	client internalclientset.Interface
+}
+
+func (s *annotationDecisionSink) RecordDecision(decision *algorithm.DecisionRecord) error {
+	if decision.UID == "" {
+		return nil
+	}
+
+	pod, err := s.client.Core().Pods(decision.Namespace).Get(decision.Name)
+	if err != nil {
+		return err
+	}
+	if pod.UID != decision.UID || pod.Spec.NodeName == "" {
+		glog.V(4).Infof("Not annotating Pod %s/%s with its packing decision, it is not bound", decision.Namespace, decision.Name)
+		return nil
+	}
+
+	data, err := json.Marshal(decision)
+	if err != nil {
+		return err
+	}
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]string{
+				decisionAnnotation: string(data),
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	if _, err := s.client.Core().Pods(decision.Namespace).Patch(decision.Name, api.StrategicMergePatchType, patch); err != nil {
+		return err
+	}
+	glog.V(4).Infof("Annotated Pod %s/%s with its packing decision", decision.Namespace, decision.Name)
+	return nil
+}
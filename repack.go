@@ -0,0 +1,145 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/jmccarty3/packScheduler/algorithm"
+	"github.com/spf13/pflag"
+
+	"k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/apis/policy"
+	unversionedclient "k8s.io/kubernetes/pkg/client/unversioned"
+	"k8s.io/kubernetes/plugin/cmd/kube-scheduler/app/options"
+)
+
+const (
+	// Marks a node unschedulable so the evicted pods are not placed back on it
+	cordonPatch = `{"spec":{"unschedulable":true}}`
+	// Makes a node schedulable again when it could not be emptied
+	uncordonPatch = `{"spec":{"unschedulable":false}}`
+)
+
+var (
+	repackInterval time.Duration
+	repackMaxNodes = 1
+	repackEvict    bool
+)
+
+func addRepackFlags(fs *pflag.FlagSet) {
+	fs.DurationVar(&repackInterval, "repack-interval", repackInterval, "How often to plan the pod moves that would empty the least utilized nodes. The plan is written to the log. 0 disables repacking")
+	fs.IntVar(&repackMaxNodes, "repack-max-nodes", repackMaxNodes, "Most nodes to empty in each repack")
+	fs.BoolVar(&repackEvict, "repack-evict", repackEvict, "Carry out the repack plan: cordon the nodes to empty and evict their pods, leaving the scheduler to place them again")
+}
+
+// repacker plans, and optionally carries out, the moves that empty the least utilized nodes
+type repacker struct {
+	cluster *clusterLister
+	client  *unversionedclient.Client
+}
+
+// startRepacker starts repacking the cluster every --repack-interval
+func startRepacker(s *options.SchedulerServer, cluster *clusterLister) error {
+	if repackInterval == 0 {
+		return nil
+	}
+	if repackMaxNodes <= 0 {
+		return fmt.Errorf("--repack-max-nodes must be positive: %d", repackMaxNodes)
+	}
+
+	client, err := newLegacyClient(s)
+	if err != nil {
+		return err
+	}
+
+	r := &repacker{cluster: cluster, client: client}
+	go func() {
+		for range time.Tick(repackInterval) {
+			if err := r.repack(); err != nil {
+				glog.Errorf("Repack failed: %v", err)
+			}
+		}
+	}()
+	return nil
+}
+
+func (r *repacker) repack() error {
+	nodes, pods, err := r.cluster.list()
+	if err != nil {
+		return err
+	}
+	budgets, err := listDisruptionBudgets(r.client)
+	if err != nil {
+		return err
+	}
+
+	plan, err := algorithm.PlanRepack(nodes, pods, budgets, repackMaxNodes)
+	if err != nil {
+		return err
+	}
+	for node, reason := range plan.Skipped {
+		glog.V(2).Infof("Repack: Node %s cannot be emptied: %s", node, reason)
+	}
+	for _, move := range plan.Moves {
+		glog.Infof("Repack: Pod %s/%s can move from %s to %s", move.Pod.Namespace, move.Pod.Name, move.From, move.To)
+	}
+	for _, node := range plan.Nodes {
+		glog.Infof("Repack: Node %s can be emptied", node)
+	}
+
+	if !repackEvict {
+		return nil
+	}
+	for _, node := range plan.Nodes {
+		if err := r.emptyNode(node, plan.Moves); err != nil {
+			glog.Errorf("Repack: Unable to empty node %s: %v", node, err)
+		}
+	}
+	return nil
+}
+
+// emptyNode cordons the node and evicts the pods the plan moves off it. The eviction API
+// checks the disruption budgets again, so a budget that changed since planning stops it.
+// The node is uncordoned when a pod cannot be evicted, unless it was already cordoned before
+func (r *repacker) emptyNode(node string, moves []algorithm.PodMove) error {
+	current, err := r.cluster.client.Core().Nodes().Get(node)
+	if err != nil {
+		return fmt.Errorf("Unable to get the node: %v", err)
+	}
+	cordoned := current.Spec.Unschedulable
+	if !cordoned {
+		if _, err := r.cluster.client.Core().Nodes().Patch(node, api.StrategicMergePatchType, []byte(cordonPatch)); err != nil {
+			return fmt.Errorf("Unable to cordon: %v", err)
+		}
+	}
+
+	for _, move := range moves {
+		if move.From != node {
+			continue
+		}
+
+		if err := evictPod(r.client, move.Pod); err != nil {
+			if !cordoned {
+				if _, err := r.cluster.client.Core().Nodes().Patch(node, api.StrategicMergePatchType, []byte(uncordonPatch)); err != nil {
+					glog.Errorf("Repack: Unable to uncordon node %s: %v", node, err)
+				}
+			}
+			return fmt.Errorf("Unable to evict Pod %s/%s: %v", move.Pod.Namespace, move.Pod.Name, err)
+		}
+		glog.Infof("Repack: Evicted Pod %s/%s from %s", move.Pod.Namespace, move.Pod.Name, node)
+	}
+	return nil
+}
+
+// evictPod posts an Eviction for the pod. The eviction subresource is served under the core API,
+// but the Eviction is a policy object, so it is sent with the policy client to be encoded in that group
+func evictPod(client *unversionedclient.Client, pod *api.Pod) error {
+	eviction := &policy.Eviction{
+		ObjectMeta: api.ObjectMeta{
+			Namespace: pod.Namespace,
+			Name:      pod.Name,
+		},
+	}
+	return client.PolicyClient.Post().AbsPath("/api/v1").Namespace(pod.Namespace).Resource("pods").Name(pod.Name).SubResource("eviction").Body(eviction).Do().Error()
+}
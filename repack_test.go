@@ -0,0 +1,55 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/client/restclient"
+	unversionedclient "k8s.io/kubernetes/pkg/client/unversioned"
+)
+
+func TestEvictPod(t *testing.T) {
+	tests := []struct {
+		testName string
+		status   int
+		err      bool
+	}{
+		{"Evicted", http.StatusCreated, false},
+		{"DisruptionBudget", http.StatusTooManyRequests, true},
+	}
+
+	for _, test := range tests {
+		var path string
+		body := map[string]interface{}{}
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			path = r.URL.Path
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				t.Errorf("Test %s. Unable to decode the eviction: %v", test.testName, err)
+			}
+			w.WriteHeader(test.status)
+		}))
+
+		client, err := unversionedclient.New(&restclient.Config{Host: server.URL})
+		if err != nil {
+			t.Fatalf("Unexpected error %v", err)
+		}
+		err = evictPod(client, &api.Pod{ObjectMeta: api.ObjectMeta{Namespace: "default", Name: "web"}})
+		server.Close()
+
+		if test.err && err == nil {
+			t.Errorf("Test %s. Expected an error", test.testName)
+		}
+		if !test.err && err != nil {
+			t.Errorf("Test %s. Unexpected error %v", test.testName, err)
+		}
+		if path != "/api/v1/namespaces/default/pods/web/eviction" {
+			t.Errorf("Test %s. Unexpected path %s", test.testName, path)
+		}
+		if body["apiVersion"] != "policy/v1alpha1" || body["kind"] != "Eviction" {
+			t.Errorf("Test %s. Expected a policy/v1alpha1 Eviction, got %v %v", test.testName, body["apiVersion"], body["kind"])
+		}
+	}
+}
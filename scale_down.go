@@ -0,0 +1,157 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/jmccarty3/packScheduler/algorithm"
+	"github.com/spf13/pflag"
+
+	"k8s.io/kubernetes/pkg/api"
+	unversionedclient "k8s.io/kubernetes/pkg/client/unversioned"
+	"k8s.io/kubernetes/plugin/cmd/kube-scheduler/app/options"
+)
+
+var (
+	scaleDownInterval time.Duration
+	scaleDownTaint    bool
+)
+
+func addScaleDownFlags(fs *pflag.FlagSet) {
+	fs.DurationVar(&scaleDownInterval, "scale-down-interval", scaleDownInterval, "How often to mark the nodes that are empty or could be drained with the "+algorithm.ScaleDownCandidateAnnotation+" annotation. 0 disables marking")
+	fs.BoolVar(&scaleDownTaint, "scale-down-taint", scaleDownTaint, "Also put a PreferNoSchedule taint on the nodes marked for scale-down")
+}
+
+// scaleDownMarker keeps the scale-down annotation and taint of every node up to date
+type scaleDownMarker struct {
+	cluster *clusterLister
+	client  *unversionedclient.Client
+}
+
+// startScaleDownMarker starts marking the scale-down candidates every --scale-down-interval
+func startScaleDownMarker(s *options.SchedulerServer, cluster *clusterLister) error {
+	if scaleDownInterval == 0 {
+		return nil
+	}
+
+	client, err := newLegacyClient(s)
+	if err != nil {
+		return err
+	}
+
+	m := &scaleDownMarker{cluster: cluster, client: client}
+	go func() {
+		for range time.Tick(scaleDownInterval) {
+			if err := m.mark(); err != nil {
+				glog.Errorf("Marking scale-down candidates failed: %v", err)
+			}
+		}
+	}()
+	return nil
+}
+
+func (m *scaleDownMarker) mark() error {
+	nodes, pods, err := m.cluster.list()
+	if err != nil {
+		return err
+	}
+	budgets, err := listDisruptionBudgets(m.client)
+	if err != nil {
+		return err
+	}
+	candidates, err := algorithm.ScaleDownCandidates(nodes, pods, budgets)
+	if err != nil {
+		return err
+	}
+
+	// Unschedulable nodes are not candidates, but may still carry an old mark
+	nodeList, err := m.cluster.client.Core().Nodes().List(api.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("Unable to list nodes: %v", err)
+	}
+	for i := range nodeList.Items {
+		node := &nodeList.Items[i]
+		if err := m.markNode(node, candidates[node.Name]); err != nil {
+			glog.Errorf("Unable to mark node %s for scale-down: %v", node.Name, err)
+		}
+	}
+	return nil
+}
+
+// markNode sets the annotation and taint of the node to 'state', removing them when it is empty
+func (m *scaleDownMarker) markNode(node *api.Node, state algorithm.ScaleDownState) error {
+	annotations := map[string]interface{}{}
+	if current := algorithm.ScaleDownState(node.Annotations[algorithm.ScaleDownCandidateAnnotation]); current != state {
+		if state == "" {
+			annotations[algorithm.ScaleDownCandidateAnnotation] = nil
+		} else {
+			annotations[algorithm.ScaleDownCandidateAnnotation] = string(state)
+		}
+	}
+
+	if scaleDownTaint {
+		taints, changed, err := scaleDownTaints(node, state != "")
+		if err != nil {
+			return err
+		}
+		if changed {
+			annotations[api.TaintsAnnotationKey] = taints
+		}
+	}
+
+	if len(annotations) == 0 {
+		return nil
+	}
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": annotations,
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	if _, err := m.cluster.client.Core().Nodes().Patch(node.Name, api.StrategicMergePatchType, patch); err != nil {
+		return err
+	}
+	glog.V(2).Infof("Marked node %s for scale-down: %q", node.Name, state)
+	return nil
+}
+
+// scaleDownTaints returns the taints annotation of the node with the scale-down taint added or removed,
+// and whether that changed the taints
+func scaleDownTaints(node *api.Node, tainted bool) (string, bool, error) {
+	taints, err := api.GetTaintsFromNodeAnnotations(node.Annotations)
+	if err != nil {
+		return "", false, err
+	}
+
+	updated := []api.Taint{}
+	found := false
+	for _, taint := range taints {
+		if taint.Key == algorithm.ScaleDownCandidateAnnotation {
+			found = true
+			if !tainted {
+				continue
+			}
+		}
+		updated = append(updated, taint)
+	}
+	if found == tainted {
+		return "", false, nil
+	}
+	if tainted {
+		updated = append(updated, api.Taint{
+			Key:    algorithm.ScaleDownCandidateAnnotation,
+			Effect: api.TaintEffectPreferNoSchedule,
+		})
+	}
+
+	data, err := json.Marshal(updated)
+	if err != nil {
+		return "", false, err
+	}
+	return string(data), true, nil
+}
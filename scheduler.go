@@ -2,9 +2,13 @@ package main
 
 import (
 	"flag"
+	"fmt"
+	"os"
 	"runtime"
 
-	_ "github.com/jmccarty3/packScheduler/algorithm"
+	"github.com/golang/glog"
+	"github.com/jmccarty3/packScheduler/algorithm"
+	"github.com/prometheus/client_golang/prometheus"
 
 	"k8s.io/kubernetes/pkg/healthz"
 	k8sFlag "k8s.io/kubernetes/pkg/util/flag"
@@ -22,8 +26,21 @@ func init() {
 
 func main() {
 	runtime.GOMAXPROCS(runtime.NumCPU())
+	if len(os.Args) > 1 && os.Args[1] == simulateCommand {
+		if err := runSimulate(os.Args[2:], os.Stdout); err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", simulateCommand, err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	s := options.NewSchedulerServer()
 	s.AddFlags(pflag.CommandLine)
+	algorithm.AddFlags(pflag.CommandLine)
+	addExplainFlags(pflag.CommandLine)
+	addDebugFlags(pflag.CommandLine)
+	addRepackFlags(pflag.CommandLine)
+	addScaleDownFlags(pflag.CommandLine)
 
 	k8sFlag.InitFlags()
 	logs.InitLogs()
@@ -32,5 +49,26 @@ func main() {
 	verflag.PrintAndExitIfRequested()
 	// Trick to avoid 'logging before flag.Parse' warning
 	flag.CommandLine.Parse([]string{})
+	if err := algorithm.LoadPolicy(); err != nil {
+		glog.Fatalf("Failed to load packing policy: %v", err)
+	}
+
+	client, err := newClient(s)
+	if err != nil {
+		glog.Fatalf("Failed to create client: %v", err)
+	}
+	cluster := newClusterLister(client, s.SchedulerName)
+	prometheus.MustRegister(algorithm.NewClusterCollector(cluster.list))
+
+	if err := setupExplain(client); err != nil {
+		glog.Fatalf("Failed to set up explain mode: %v", err)
+	}
+	startDebugServer(cluster)
+	if err := startRepacker(s, cluster); err != nil {
+		glog.Fatalf("Failed to start repacking: %v", err)
+	}
+	if err := startScaleDownMarker(s, cluster); err != nil {
+		glog.Fatalf("Failed to start marking scale-down candidates: %v", err)
+	}
 	app.Run(s)
 }
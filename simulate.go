@@ -0,0 +1,244 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/ghodss/yaml"
+	"github.com/jmccarty3/packScheduler/algorithm"
+	"github.com/spf13/pflag"
+
+	"k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/api/resource"
+	"k8s.io/kubernetes/pkg/api/v1"
+	"k8s.io/kubernetes/pkg/runtime"
+)
+
+const simulateCommand = "simulate"
+
+// simulateOptions are the flags of the simulate command
+type simulateOptions struct {
+	snapshotFile string
+	nodesFile    string
+	podsFile     string
+	pendingFile  string
+	predicates   []string
+	priorities   string
+}
+
+// runSimulate places the pending pods on a snapshot of the cluster with the packing predicates and
+// priorities and prints where they went
+func runSimulate(args []string, out io.Writer) error {
+	o := &simulateOptions{
+		predicates: algorithm.PredicateNames(),
+		priorities: "MostUsed=1",
+	}
+
+	fs := pflag.NewFlagSet(simulateCommand, pflag.ExitOnError)
+	fs.StringVar(&o.snapshotFile, "snapshot", o.snapshotFile, "Snapshot of the nodes and their pods, e.g. from the "+debugSnapshotPath+" endpoint. Replaces --nodes and --pods")
+	fs.StringVar(&o.nodesFile, "nodes", o.nodesFile, "YAML or JSON file with the nodes of the cluster, e.g. the output of kubectl get nodes -o yaml")
+	fs.StringVar(&o.podsFile, "pods", o.podsFile, "YAML or JSON file with the pods already running. Pods without a node are ignored")
+	fs.StringVar(&o.pendingFile, "pending", o.pendingFile, "YAML or JSON file with the pods to schedule, in order")
+	fs.StringSliceVar(&o.predicates, "predicates", o.predicates, "Predicates to check nodes with. Available: "+strings.Join(algorithm.PredicateNames(), ", "))
+	fs.StringVar(&o.priorities, "priorities", o.priorities, "Priorities to score nodes with and their weights, e.g. MostUsed=1,AppTopologySpread=2. Available: "+strings.Join(algorithm.PriorityNames(), ", "))
+	algorithm.AddFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if err := algorithm.LoadPolicy(); err != nil {
+		return err
+	}
+	if (o.nodesFile == "" && o.snapshotFile == "") || o.pendingFile == "" {
+		return fmt.Errorf("--pending and either --snapshot or --nodes are required")
+	}
+
+	priorities, err := parsePriorityWeights(o.priorities)
+	if err != nil {
+		return err
+	}
+
+	nodes, pods, err := o.loadCluster()
+	if err != nil {
+		return err
+	}
+	pending, err := loadPods(o.pendingFile)
+	if err != nil {
+		return err
+	}
+
+	simulator, err := algorithm.NewSimulator(nodes, pods, o.predicates, priorities)
+	if err != nil {
+		return err
+	}
+	placements, err := simulator.Simulate(pending)
+	if err != nil {
+		return err
+	}
+
+	printPlacements(out, placements)
+	fmt.Fprintln(out)
+	printUtilization(out, simulator.Utilization())
+	fmt.Fprintf(out, "\nEmpty nodes: %d of %d\n", simulator.EmptyNodes(), len(nodes))
+	return nil
+}
+
+// loadCluster loads the nodes and pods from the snapshot, or from the node and pod files
+func (o *simulateOptions) loadCluster() ([]*api.Node, []*api.Pod, error) {
+	if o.snapshotFile != "" {
+		file, err := os.Open(o.snapshotFile)
+		if err != nil {
+			return nil, nil, fmt.Errorf("Unable to read %s: %v", o.snapshotFile, err)
+		}
+		defer file.Close()
+
+		snapshot, err := algorithm.ReadSnapshot(file)
+		if err != nil {
+			return nil, nil, err
+		}
+		return snapshot.NodeList(), snapshot.Pods(), nil
+	}
+
+	nodes := []*api.Node{}
+	err := loadObjects(o.nodesFile, func(data []byte) error {
+		node := &api.Node{}
+		nodes = append(nodes, node)
+		return decodeObject(data, "Node", node)
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	pods := []*api.Pod{}
+	if o.podsFile != "" {
+		if pods, err = loadPods(o.podsFile); err != nil {
+			return nil, nil, err
+		}
+	}
+	return nodes, pods, nil
+}
+
+// parsePriorityWeights parses a list of priorities written as <name>=<weight>,<name>=<weight>
+func parsePriorityWeights(value string) (map[string]int, error) {
+	weights := map[string]int{}
+	for _, pair := range strings.Split(value, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+
+		parts := strings.SplitN(pair, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("Invalid priority %q, expected <name>=<weight>", pair)
+		}
+		weight, err := strconv.Atoi(strings.TrimSpace(parts[1]))
+		if err != nil {
+			return nil, fmt.Errorf("Invalid weight for priority %s: %v", parts[0], err)
+		}
+		weights[strings.TrimSpace(parts[0])] = weight
+	}
+	return weights, nil
+}
+
+// loadObjects calls 'decode' with the JSON of every object in the file. The file holds either a
+// single object or a list with items, in YAML or JSON.
+func loadObjects(path string, decode func(data []byte) error) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("Unable to read %s: %v", path, err)
+	}
+	if data, err = yaml.YAMLToJSON(data); err != nil {
+		return fmt.Errorf("Unable to parse %s: %v", path, err)
+	}
+
+	list := struct {
+		Items []json.RawMessage `json:"items"`
+	}{}
+	if err := json.Unmarshal(data, &list); err != nil {
+		return fmt.Errorf("Unable to parse %s: %v", path, err)
+	}
+	if list.Items == nil {
+		list.Items = []json.RawMessage{data}
+	}
+
+	for _, item := range list.Items {
+		if err := decode(item); err != nil {
+			return fmt.Errorf("Unable to parse %s: %v", path, err)
+		}
+	}
+	return nil
+}
+
+// decodeObject decodes the JSON of a v1 object into its internal type. Items of a list may leave out
+// their apiVersion and kind, which then default to v1 and 'kind'
+func decodeObject(data []byte, kind string, into runtime.Object) error {
+	defaults := v1.SchemeGroupVersion.WithKind(kind)
+	_, _, err := api.Codecs.UniversalDecoder().Decode(data, &defaults, into)
+	return err
+}
+
+func loadPods(path string) ([]*api.Pod, error) {
+	pods := []*api.Pod{}
+	err := loadObjects(path, func(data []byte) error {
+		pod := &api.Pod{}
+		pods = append(pods, pod)
+		if err := decodeObject(data, "Pod", pod); err != nil {
+			return err
+		}
+		if pod.Namespace == "" {
+			pod.Namespace = api.NamespaceDefault
+		}
+		return nil
+	})
+	return pods, err
+}
+
+func printPlacements(out io.Writer, placements []algorithm.SimulatedPlacement) {
+	w := tabwriter.NewWriter(out, 0, 8, 2, ' ', 0)
+	defer w.Flush()
+
+	fmt.Fprintln(w, "POD\tNODE")
+	for _, placement := range placements {
+		node := placement.Node
+		if node == "" {
+			failures := []string{}
+			for reason, count := range placement.Failures {
+				failures = append(failures, fmt.Sprintf("%d %s", count, reason))
+			}
+			sort.Strings(failures)
+			node = fmt.Sprintf("<none: %s>", strings.Join(failures, ", "))
+		}
+		fmt.Fprintf(w, "%s/%s\t%s\n", placement.Pod.Namespace, placement.Pod.Name, node)
+	}
+}
+
+func printUtilization(out io.Writer, utilization []algorithm.NodeUtilization) {
+	w := tabwriter.NewWriter(out, 0, 8, 2, ' ', 0)
+	defer w.Flush()
+
+	fmt.Fprintln(w, "NODE\tPODS\tCPU\tMEMORY")
+	for _, node := range utilization {
+		cpu := resource.NewMilliQuantity(node.Requested[api.ResourceCPU], resource.DecimalSI)
+		cpuCapacity := resource.NewMilliQuantity(node.Capacity[api.ResourceCPU], resource.DecimalSI)
+		memory := resource.NewQuantity(node.Requested[api.ResourceMemory], resource.BinarySI)
+		memoryCapacity := resource.NewQuantity(node.Capacity[api.ResourceMemory], resource.BinarySI)
+
+		fmt.Fprintf(w, "%s\t%d\t%s/%s (%d%%)\t%s/%s (%d%%)\n", node.Node, node.Pods,
+			cpu.String(), cpuCapacity.String(), percent(node.Requested[api.ResourceCPU], node.Capacity[api.ResourceCPU]),
+			memory.String(), memoryCapacity.String(), percent(node.Requested[api.ResourceMemory], node.Capacity[api.ResourceMemory]),
+		)
+	}
+}
+
+func percent(requested, capacity int64) int64 {
+	if capacity == 0 {
+		return 0
+	}
+	return requested * 100 / capacity
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"k8s.io/kubernetes/pkg/api"
+)
+
+func TestLoadPods(t *testing.T) {
+	file, err := ioutil.TempFile("", "pods")
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	defer os.Remove(file.Name())
+
+	// The items of a list from the API server leave out their apiVersion and kind. The v1 API of
+	// Kubernetes 1.4 only carries init containers in their alpha annotation
+	_, err = file.WriteString(`{
+  "apiVersion": "v1",
+  "kind": "PodList",
+  "items": [
+    {
+      "metadata": {
+        "name": "web",
+        "annotations": {
+          "pod.alpha.kubernetes.io/init-containers": "[{\"name\": \"migrate\", \"resources\": {\"requests\": {\"cpu\": \"2\"}}}]"
+        }
+      },
+      "spec": {"containers": [{"name": "web"}]}
+    },
+    {
+      "apiVersion": "v1",
+      "kind": "Pod",
+      "metadata": {"name": "worker", "namespace": "jobs"},
+      "spec": {"containers": [{"name": "worker"}]}
+    }
+  ]
+}`)
+	file.Close()
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	pods, err := loadPods(file.Name())
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	if len(pods) != 2 {
+		t.Fatalf("Expected 2 pods, got %d", len(pods))
+	}
+	if pods[0].Namespace != api.NamespaceDefault || pods[1].Namespace != "jobs" {
+		t.Errorf("Expected the namespaces default and jobs, got %s and %s", pods[0].Namespace, pods[1].Namespace)
+	}
+	if len(pods[0].Spec.InitContainers) != 1 || pods[0].Spec.InitContainers[0].Name != "migrate" {
+		t.Errorf("Expected the init container migrate, got %+v", pods[0].Spec.InitContainers)
+	}
+}